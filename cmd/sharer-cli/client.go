@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Client talks to the Sharer JSON API
+type Client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// PageCreate represents the request body for creating a page
+type PageCreate struct {
+	HTMLContent string     `json:"html_content"`
+	Title       string     `json:"title,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Password    string     `json:"password,omitempty"`
+	Live        bool       `json:"live,omitempty"`
+}
+
+// PageUpdate represents the request body for updating a page
+type PageUpdate struct {
+	HTMLContent *string `json:"html_content,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	CategoryID  *uint   `json:"category_id,omitempty"`
+	Live        *bool   `json:"live,omitempty"`
+}
+
+// PageCreated represents the response to a page creation
+type PageCreated struct {
+	URL       string `json:"url"`
+	Slug      string `json:"slug"`
+	EditToken string `json:"edit_token"`
+	// Quarantined is set when the content scanner held the page for review
+	Quarantined bool `json:"quarantined"`
+}
+
+// PageUpdated represents the response to a page update
+type PageUpdated struct {
+	// Quarantined is set when the page is held for review and not served
+	Quarantined bool `json:"quarantined"`
+}
+
+// Page represents a page as listed by the API
+type Page struct {
+	ID           uint      `json:"id"`
+	Slug         string    `json:"slug"`
+	Title        string    `json:"title"`
+	CategoryName *string   `json:"category_name"`
+	Tags         []string  `json:"tags"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PageList represents a page of list results
+type PageList struct {
+	Data       []*Page `json:"data"`
+	Pagination struct {
+		Page       int   `json:"page"`
+		Total      int64 `json:"total"`
+		TotalPages int64 `json:"total_pages"`
+	} `json:"pagination"`
+}
+
+// Category represents a category returned by the API
+type Category struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// APIError represents an error body returned by the API
+type APIError struct {
+	Status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server returned %d", e.Status)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Status)
+}
+
+// NewClient creates a client for the server at baseURL
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		server: baseURL,
+		token:  token,
+		http:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// PageURL returns the public URL of a page
+func (c *Client) PageURL(slug string) string {
+	return c.server + "/shared/" + url.PathEscape(slug)
+}
+
+// CreatePage uploads a new page
+func (c *Client) CreatePage(req *PageCreate) (*PageCreated, error) {
+	var created PageCreated
+	if err := c.do(http.MethodPost, "/api/v1/pages", nil, req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdatePage updates a page using its edit token
+func (c *Client) UpdatePage(slug, editToken string, req *PageUpdate) (*PageUpdated, error) {
+	var updated PageUpdated
+	headers := map[string]string{"X-Edit-Token": editToken}
+	if err := c.do(http.MethodPatch, "/api/v1/pages/"+url.PathEscape(slug), headers, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeletePage deletes a page using its edit token
+func (c *Client) DeletePage(slug, editToken string) error {
+	headers := map[string]string{"X-Edit-Token": editToken}
+	return c.do(http.MethodDelete, "/api/v1/pages/"+url.PathEscape(slug), headers, nil, nil)
+}
+
+// ListPages lists pages matching the query parameters
+func (c *Client) ListPages(query url.Values) (*PageList, error) {
+	var list PageList
+	if err := c.do(http.MethodGet, "/api/v1/pages?"+query.Encode(), nil, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ResolveCategory returns the ID of a category given by ID or by name
+func (c *Client) ResolveCategory(ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		return uint(id), nil
+	}
+
+	var category Category
+	if err := c.do(http.MethodGet, "/api/v1/categories/lookup?name="+url.QueryEscape(ref), nil, nil, &category); err != nil {
+		return 0, fmt.Errorf("category %q: %w", ref, err)
+	}
+	return category.ID, nil
+}
+
+// do sends a JSON request and decodes the JSON response into out
+func (c *Client) do(method, path string, headers map[string]string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var errorBody struct {
+			Error APIError `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errorBody)
+		errorBody.Error.Status = resp.StatusCode
+		return &errorBody.Error
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// app holds the state shared by all subcommands
+type app struct {
+	client *Client
+	state  *State
+}
+
+// newFlagSet creates a flag set for a subcommand with a one-line usage string
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sharer-cli %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// readContent reads page content from a file, or from stdin when path is "-"
+func readContent(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return string(data), nil
+}
+
+// parseExpiry parses a Go duration, also accepting a whole number of days such as "7d"
+func parseExpiry(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid expiry %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid expiry %q", value)
+	}
+	return d, nil
+}
+
+// editToken returns the stored edit token for a slug, preferring an explicit token
+func (a *app) editToken(slug, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if page, ok := a.state.Pages[slug]; ok {
+		return page.EditToken, nil
+	}
+	return "", fmt.Errorf("no edit token stored for %q; pass --edit-token", slug)
+}
+
+// pushOptions holds the flags shared by push and watch
+type pushOptions struct {
+	title    *string
+	category *string
+	expiry   *string
+	password *string
+}
+
+// addPushFlags registers the page creation flags on a flag set
+func addPushFlags(fs *flag.FlagSet) *pushOptions {
+	return &pushOptions{
+		title:    fs.String("title", "", "page title (default: file name)"),
+		category: fs.String("category", "", "category ID or name"),
+		expiry:   fs.String("expiry", "", "expire the page after a duration, e.g. 12h or 7d"),
+		password: fs.String("password", "", "require a password to view the page"),
+	}
+}
+
+// heldForReview explains why a page that was saved cannot be viewed yet
+const heldForReview = "The page was held for review by the content scanner and will be served once a moderator approves it"
+
+// createPage uploads content read from path and records the new page's edit token
+func (a *app) createPage(opts *pushOptions, path, content string, live bool) (*PageCreated, error) {
+	req := &PageCreate{HTMLContent: content, Title: *opts.title, Password: *opts.password, Live: live}
+	if req.Title == "" && path != "-" {
+		req.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if *opts.category != "" {
+		id, err := a.client.ResolveCategory(*opts.category)
+		if err != nil {
+			return nil, err
+		}
+		req.CategoryID = &id
+	}
+	if *opts.expiry != "" {
+		d, err := parseExpiry(*opts.expiry)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := time.Now().Add(d).UTC()
+		req.ExpiresAt = &expiresAt
+	}
+
+	created, err := a.client.CreatePage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	source := ""
+	if path != "-" {
+		if abs, err := filepath.Abs(path); err == nil {
+			source = abs
+		}
+	}
+	a.state.Pages[created.Slug] = &PageState{URL: created.URL, EditToken: created.EditToken, Source: source}
+	if err := a.state.Save(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// runPush uploads a new page and records its edit token
+func runPush(a *app, args []string) error {
+	fs := newFlagSet("push", "<file|->")
+	opts := addPushFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("push takes exactly one file")
+	}
+
+	content, err := readContent(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	created, err := a.createPage(opts, fs.Arg(0), content, false)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(created.URL)
+	if created.Quarantined {
+		fmt.Fprintln(os.Stderr, heldForReview)
+	}
+	return nil
+}
+
+// runUpdate replaces the content of a page pushed from this machine
+func runUpdate(a *app, args []string) error {
+	fs := newFlagSet("update", "<slug> <file|->")
+	title := fs.String("title", "", "new page title")
+	token := fs.String("edit-token", "", "edit token (default: the token stored by push)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("update takes a slug and a file")
+	}
+
+	slug := fs.Arg(0)
+	editToken, err := a.editToken(slug, *token)
+	if err != nil {
+		return err
+	}
+
+	content, err := readContent(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	req := &PageUpdate{HTMLContent: &content}
+	if *title != "" {
+		req.Title = title
+	}
+	updated, err := a.client.UpdatePage(slug, editToken, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(a.client.PageURL(slug))
+	if updated.Quarantined {
+		fmt.Fprintln(os.Stderr, heldForReview)
+	}
+	return nil
+}
+
+// runList prints pages on the server, marking those pushed from this machine
+func runList(a *app, args []string) error {
+	fs := newFlagSet("ls", "")
+	category := fs.String("category", "", "only pages in this category (ID or name)")
+	tag := fs.String("tag", "", "only pages with this tag")
+	query := fs.String("q", "", "search titles and slugs")
+	page := fs.Int("page", 1, "page number")
+	pageSize := fs.Int("page-size", 20, "results per page")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(*page))
+	params.Set("page_size", strconv.Itoa(*pageSize))
+	if *category != "" {
+		id, err := a.client.ResolveCategory(*category)
+		if err != nil {
+			return err
+		}
+		params.Set("category_id", strconv.FormatUint(uint64(id), 10))
+	}
+	if *tag != "" {
+		params.Set("tag", *tag)
+	}
+	if *query != "" {
+		params.Set("q", *query)
+	}
+
+	list, err := a.client.ListPages(params)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tSLUG\tTITLE\tCATEGORY\tTAGS\tCREATED")
+	for _, p := range list.Data {
+		mine := ""
+		if _, ok := a.state.Pages[p.Slug]; ok {
+			mine = "*"
+		}
+		categoryName := "-"
+		if p.CategoryName != nil {
+			categoryName = *p.CategoryName
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", mine, p.Slug, p.Title, categoryName,
+			strings.Join(p.Tags, ","), p.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\npage %d of %d, %d pages total (* = editable from this machine)\n",
+		list.Pagination.Page, list.Pagination.TotalPages, list.Pagination.Total)
+	return nil
+}
+
+// runRemove deletes pages pushed from this machine
+func runRemove(a *app, args []string) error {
+	fs := newFlagSet("rm", "<slug>...")
+	token := fs.String("edit-token", "", "edit token (default: the token stored by push)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("rm takes at least one slug")
+	}
+
+	for _, slug := range fs.Args() {
+		editToken, err := a.editToken(slug, *token)
+		if err != nil {
+			return err
+		}
+		if err := a.client.DeletePage(slug, editToken); err != nil {
+			return fmt.Errorf("%s: %w", slug, err)
+		}
+		delete(a.state.Pages, slug)
+		fmt.Println("deleted", slug)
+	}
+
+	return a.state.Save()
+}
+
+// runOpen opens a page in the default browser
+func runOpen(a *app, args []string) error {
+	fs := newFlagSet("open", "<slug>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("open takes exactly one slug")
+	}
+
+	target := a.client.PageURL(fs.Arg(0))
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser, visit %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultServer is used when neither the config file nor the environment names a server
+const DefaultServer = "http://localhost:8080"
+
+// Config holds the CLI settings read from the config file and environment
+type Config struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token"`
+}
+
+// PageState records a page pushed from this machine
+type PageState struct {
+	URL       string `json:"url"`
+	EditToken string `json:"edit_token"`
+	Source    string `json:"source,omitempty"`
+}
+
+// State holds the edit tokens of pages pushed from this machine, keyed by slug
+type State struct {
+	path  string
+	Pages map[string]*PageState `json:"pages"`
+}
+
+// configDir returns the directory holding the CLI config and state files
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "sharer"), nil
+}
+
+// LoadConfig reads the config file, then applies SHARER_SERVER and SHARER_TOKEN overrides.
+// A missing config file is not an error.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("SHARER_CONFIG")
+	}
+	if path == "" {
+		dir, err := configDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "config.yaml")
+	}
+
+	config := &Config{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if server := os.Getenv("SHARER_SERVER"); server != "" {
+		config.Server = server
+	}
+	if token := os.Getenv("SHARER_TOKEN"); token != "" {
+		config.Token = token
+	}
+	if config.Server == "" {
+		config.Server = DefaultServer
+	}
+	config.Server = strings.TrimRight(config.Server, "/")
+
+	return config, nil
+}
+
+// LoadState reads the page state file, returning an empty state when it does not exist
+func LoadState() (*State, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{path: filepath.Join(dir, "pages.json"), Pages: make(map[string]*PageState)}
+	data, err := os.ReadFile(state.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", state.path, err)
+	}
+	if state.Pages == nil {
+		state.Pages = make(map[string]*PageState)
+	}
+	return state, nil
+}
+
+// Save writes the page state file, readable only by the current user
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
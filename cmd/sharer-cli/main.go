@@ -0,0 +1,87 @@
+// Command sharer-cli pushes HTML pages to a Sharer server through its HTTP API.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: sharer-cli [global flags] <command> [flags] [args]
+
+Commands:
+  push <file|->          Upload a page and print its URL
+  update <slug> <file|-> Replace the content of a page you pushed
+  watch <file>           Push a page, then update it whenever the file changes
+  ls                     List pages on the server
+  rm <slug>...           Delete pages you pushed
+  open <slug>            Open a page in the browser
+
+Global flags:
+  --config <path>  Config file (default: $XDG_CONFIG_HOME/sharer/config.yaml)
+  --server <url>   Server URL, overrides the config file
+  --token <token>  API token, overrides the config file
+
+Run 'sharer-cli <command> -h' for command flags.
+`
+
+// command runs a subcommand with its remaining arguments
+type command func(app *app, args []string) error
+
+var commands = map[string]command{
+	"push":   runPush,
+	"update": runUpdate,
+	"watch":  runWatch,
+	"ls":     runList,
+	"rm":     runRemove,
+	"open":   runOpen,
+}
+
+func main() {
+	global := flag.NewFlagSet("sharer-cli", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configPath := global.String("config", "", "config file")
+	server := global.String("server", "", "server URL")
+	token := global.String("token", "", "API token")
+
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "sharer-cli: unknown command %q\n\n", args[0])
+		global.Usage()
+		os.Exit(2)
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sharer-cli:", err)
+		os.Exit(1)
+	}
+	if *server != "" {
+		config.Server = *server
+	}
+	if *token != "" {
+		config.Token = *token
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sharer-cli:", err)
+		os.Exit(1)
+	}
+
+	app := &app{client: NewClient(config.Server, config.Token), state: state}
+	if err := run(app, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "sharer-cli:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long the file must stay quiet before a change is pushed,
+// so that editors writing in several steps produce a single update
+const watchDebounce = 300 * time.Millisecond
+
+// runWatch pushes a file as a live page and updates it whenever the file changes
+func runWatch(a *app, args []string) error {
+	fs := newFlagSet("watch", "<file>")
+	opts := addPushFlags(fs)
+	slug := fs.String("slug", "", "keep updating an existing page instead of creating one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || fs.Arg(0) == "-" {
+		fs.Usage()
+		return errors.New("watch takes exactly one file")
+	}
+
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	content, err := readContent(path)
+	if err != nil {
+		return err
+	}
+	lastSum := sha256.Sum256([]byte(content))
+
+	var editToken string
+	var held bool
+	if *slug != "" {
+		if editToken, err = a.editToken(*slug, ""); err != nil {
+			return err
+		}
+		// The page may not have been created live, and browsers only reload live pages
+		live := true
+		updated, err := a.client.UpdatePage(*slug, editToken, &PageUpdate{HTMLContent: &content, Live: &live})
+		if err != nil {
+			return err
+		}
+		held = updated.Quarantined
+	} else {
+		created, err := a.createPage(opts, path, content, true)
+		if err != nil {
+			return err
+		}
+		*slug, editToken, held = created.Slug, created.EditToken, created.Quarantined
+	}
+
+	// Watch the directory rather than the file, because many editors save by
+	// writing a new file and renaming it over the old one
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	fmt.Println(a.client.PageURL(*slug))
+	if held {
+		fmt.Fprintln(os.Stderr, heldForReview)
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s, press Ctrl+C to stop\n", path)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce.Reset(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, "watch error:", err)
+
+		case <-debounce.C:
+			content, err := readContent(path)
+			if err != nil {
+				// The file may be mid-save; the next event will retry
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+
+			sum := sha256.Sum256([]byte(content))
+			if sum == lastSum {
+				continue
+			}
+
+			updated, err := a.client.UpdatePage(*slug, editToken, &PageUpdate{HTMLContent: &content})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "update failed:", err)
+				continue
+			}
+			lastSum = sum
+			fmt.Fprintf(os.Stderr, "%s updated\n", time.Now().Format("15:04:05"))
+			if updated.Quarantined && !held {
+				fmt.Fprintln(os.Stderr, heldForReview)
+			}
+			held = updated.Quarantined
+
+		case <-interrupt:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharer/internal/archive"
+	"sharer/internal/config"
+	"sharer/internal/database"
+	"sharer/internal/logging"
+	"sharer/internal/modules/category"
+	"sharer/internal/modules/page"
+	"sharer/internal/modules/user"
+	"sharer/internal/scanner"
+)
+
+// command is a subcommand of the sharer binary
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"serve":   {"serve", "Migrate the database and start the HTTP server (default)", runServe},
+		"migrate": {"migrate", "Apply database migrations and exit", runMigrate},
+		"backup":  {"backup --to <file>", "Write an online copy of the database", runBackup},
+		"export":  {"export --format=zip [--out <file>]", "Export all pages to an archive", runExport},
+		"import":  {"import <file>", "Import pages from an export archive", runImport},
+		"purge":   {"purge --deleted-before <date|duration>", "Permanently remove soft-deleted pages", runPurge},
+		"stats":   {"stats", "Print page and category statistics", runStats},
+		"role":    {"role <username|email> <admin|member>", "Set a user's role", runRole},
+		"scan":    {"scan <file>...", "Score HTML files with the content scanner rules", runScan},
+		"help":    {"help", "Show this help", runHelp},
+	}
+}
+
+// commandOrder lists commands in the order they are shown in the help
+var commandOrder = []string{"serve", "migrate", "backup", "export", "import", "purge", "stats", "role", "scan", "help"}
+
+// printUsage prints the list of commands
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: sharer <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, name := range commandOrder {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.usage, cmd.description)
+	}
+	w.Flush()
+}
+
+// newFlagSet creates a flag set for a command
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: sharer %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadConfig parses a command's flags, including the configuration flags, and loads the configuration
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	loader := config.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.Server.LogFormat, cfg.Server.LogLevel))
+	return cfg, nil
+}
+
+// openDatabase connects to the database, optionally bringing its schema up to date
+func openDatabase(cfg *config.Config, migrate bool) (*gorm.DB, error) {
+	dbConfig := database.Config{
+		DSN:     cfg.Database.Path,
+		Pragmas: cfg.Database.Pragmas,
+		LogMode: cfg.GormLogLevel(),
+	}
+
+	db, err := database.NewConnection(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if migrate {
+		if err := database.Migrate(db); err != nil {
+			database.Close(db)
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// pageConfig returns the page service settings from the configuration
+func pageConfig(cfg *config.Config) page.Config {
+	return page.Config{
+		SlugLength:      cfg.Pages.SlugLength,
+		MaxContentBytes: cfg.Uploads.MaxBytes,
+		LiveReload:      cfg.Features.LiveReload,
+		Passwords:       cfg.Features.Passwords,
+		ReportBanner:    cfg.Features.ReportBanner,
+	}
+}
+
+// scanningPageConfig returns the page service settings from the configuration,
+// with the content scanner when it is enabled
+func scanningPageConfig(cfg *config.Config) (page.Config, error) {
+	pageCfg := pageConfig(cfg)
+	if cfg.Scanner.Enabled {
+		var err error
+		if pageCfg.Scanner, err = contentScanner(cfg); err != nil {
+			return pageCfg, fmt.Errorf("content scanner: %w", err)
+		}
+	}
+	return pageCfg, nil
+}
+
+// contentScanner creates the content scanner from the configured rules. Forms and
+// redirects pointing back at the server's own domain are never foreign.
+func contentScanner(cfg *config.Config) (*scanner.Scanner, error) {
+	rules, err := scanner.LoadRules(cfg.Scanner.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(cfg.Server.BaseURL); err == nil && u.Hostname() != "" {
+		rules.AllowedDomains = append(rules.AllowedDomains, u.Hostname())
+	}
+	return scanner.New(rules)
+}
+
+// userConfig returns the user service settings from the configuration
+func userConfig(cfg *config.Config) user.Config {
+	return user.Config{
+		SessionLifetime: time.Duration(cfg.Accounts.SessionLifetime),
+		Registration:    cfg.Accounts.Registration,
+	}
+}
+
+// newPageService creates a page service for a maintenance command
+func newPageService(db *gorm.DB, cfg *config.Config) page.Service {
+	return page.NewService(page.NewRepository(db), pageConfig(cfg))
+}
+
+// runHelp prints the list of commands
+func runHelp(args []string) error {
+	printUsage()
+	return nil
+}
+
+// runMigrate applies database migrations
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	fmt.Println("Database is up to date")
+	return nil
+}
+
+// runBackup writes a copy of the database to a new file
+func runBackup(args []string) error {
+	fs := newFlagSet("backup")
+	to := fs.String("to", "", "path of the backup file to create")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		fs.Usage()
+		return errors.New("backup: --to is required")
+	}
+	if _, err := os.Stat(*to); err == nil {
+		return fmt.Errorf("backup: %s already exists", *to)
+	}
+
+	db, err := openDatabase(cfg, false)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	if err := database.Backup(db, *to); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backed up database to %s\n", *to)
+	return nil
+}
+
+// runExport writes all pages to an archive
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", "zip", "archive format (zip)")
+	out := fs.String("out", "", "output file (default: sharer-export-<date>.zip)")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *format != "zip" {
+		return fmt.Errorf("export: unsupported format %q", *format)
+	}
+	if *out == "" {
+		*out = "sharer-export-" + time.Now().Format("20060102-150405") + ".zip"
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	count, err := archive.Export(context.Background(), f, newPageService(db, cfg))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Printf("Exported %d pages to %s\n", count, *out)
+	return nil
+}
+
+// runImport creates pages from an export archive
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("import: exactly one archive is required")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	// Imported content is scanned as if it were being shared for the first time
+	pageCfg, err := scanningPageConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	pageService := page.NewService(page.NewRepository(db), pageCfg)
+	categoryService := category.NewService(category.NewRepository(db))
+
+	result, err := archive.Import(context.Background(), f, info.Size(), pageService, categoryService)
+	if result != nil {
+		for _, message := range result.Errors {
+			fmt.Fprintln(os.Stderr, "skipped", message)
+		}
+		fmt.Printf("Imported %d pages (%d under new slugs), created %d categories\n",
+			result.Imported, result.Renamed, result.CategoriesCreated)
+	}
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	return nil
+}
+
+// runPurge permanently removes pages soft-deleted before a cutoff
+func runPurge(args []string) error {
+	fs := newFlagSet("purge")
+	deletedBefore := fs.String("deleted-before", "", "cutoff as a date (2006-01-02), RFC 3339 time, or age such as 720h or 30d")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *deletedBefore == "" {
+		fs.Usage()
+		return errors.New("purge: --deleted-before is required")
+	}
+
+	cutoff, err := parseCutoff(*deletedBefore, time.Now())
+	if err != nil {
+		return fmt.Errorf("purge: %w", err)
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	purged, err := newPageService(db, cfg).PurgeDeletedPages(context.Background(), cutoff)
+	if err != nil {
+		return fmt.Errorf("purge: %w", err)
+	}
+
+	fmt.Printf("Purged %d pages deleted before %s\n", purged, cutoff.Format(time.RFC3339))
+	return nil
+}
+
+// parseCutoff parses a date, an RFC 3339 time, or an age relative to now
+func parseCutoff(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid cutoff %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("invalid cutoff %q", value)
+		}
+		age = d
+	}
+	return now.Add(-age), nil
+}
+
+// runStats prints page and category statistics
+func runStats(args []string) error {
+	fs := newFlagSet("stats")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	ctx := context.Background()
+	stats, err := newPageService(db, cfg).GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+
+	_, categories, err := category.NewService(category.NewRepository(db)).GetCategoriesList(ctx, 1, 1, category.CategorySortName)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Active pages\t%d\n", stats.Active)
+	fmt.Fprintf(w, "Expired pages\t%d\n", stats.Expired)
+	fmt.Fprintf(w, "Deleted pages\t%d\n", stats.Deleted)
+	fmt.Fprintf(w, "Password protected\t%d\n", stats.PasswordProtected)
+	fmt.Fprintf(w, "Live pages\t%d\n", stats.Live)
+	fmt.Fprintf(w, "Content size\t%d bytes\n", stats.ContentBytes)
+	fmt.Fprintf(w, "Tags\t%d\n", stats.Tags)
+	fmt.Fprintf(w, "Categories\t%d\n", categories)
+	return w.Flush()
+}
+
+// runRole sets the role of a user, which is how the first administrator is appointed
+func runRole(args []string) error {
+	fs := newFlagSet("role")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("role: a user and a role are required")
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	userService := user.NewService(user.NewRepository(db), userConfig(cfg))
+	detail, err := userService.SetRole(context.Background(), fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("role: no user %q", fs.Arg(0))
+		}
+		return fmt.Errorf("role: %w", err)
+	}
+
+	fmt.Printf("%s is now %s\n", detail.Username, detail.Role)
+	return nil
+}
+
+// runScan scores HTML files with the content scanner, so that operators can try out their rules
+func runScan(args []string) error {
+	fs := newFlagSet("scan")
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("scan: at least one file is required")
+	}
+
+	contentScanner, err := contentScanner(cfg)
+	if err != nil {
+		return fmt.Errorf("scan: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, path := range fs.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("scan: %w", err)
+		}
+
+		result := contentScanner.Scan(string(content))
+		fmt.Fprintf(w, "%s\tscore %d\t%s\n", path, result.Score, result.Verdict)
+		for _, finding := range result.Findings {
+			fmt.Fprintf(w, "  %s\t+%d\t%s\n", finding.Rule, finding.Score, finding.Detail)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"sharer/internal/links"
+)
+
+// Error codes shared by all JSON endpoints
+const (
+	CodeBadRequest      = "bad_request"
+	CodeValidation      = "validation_failed"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodePayloadTooLarge = "payload_too_large"
+	CodeRateLimited     = "rate_limited"
+	CodeInternal        = "internal_error"
+)
+
+// Default and maximum page sizes for paginated endpoints
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Error represents the details of a failed API request
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Reason narrows down a validation failure, such as too_large or binary_content
+	Reason string `json:"reason,omitempty"`
+}
+
+// ErrorResponse represents the JSON body returned for failed API requests
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Pagination represents the pagination metadata of a list response
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// ListResponse represents the JSON body returned by list endpoints
+type ListResponse struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// AbortWithError writes a JSON error body and stops the handler chain
+func AbortWithError(ctx *gin.Context, status int, code, message string) {
+	ctx.AbortWithStatusJSON(status, ErrorResponse{Error: Error{Code: code, Message: message}})
+}
+
+// AbortWithReason writes a JSON error body with a reason that narrows down the code, and stops the handler chain
+func AbortWithReason(ctx *gin.Context, status int, code, reason, message string) {
+	ctx.AbortWithStatusJSON(status, ErrorResponse{Error: Error{Code: code, Message: message, Reason: reason}})
+}
+
+// ParsePagination reads the page and page_size query parameters, falling back to defaults
+func ParsePagination(ctx *gin.Context) (int, int) {
+	page := 1
+	if p := ctx.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := DefaultPageSize
+	if ps := ctx.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= MaxPageSize {
+			pageSize = parsed
+		}
+	}
+
+	return page, pageSize
+}
+
+// NewPagination builds pagination metadata for a list response
+func NewPagination(page, pageSize int, total int64) Pagination {
+	return Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+}
+
+// PublicURL returns middleware that records the public location of each request for link building
+func PublicURL(resolver *links.Resolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		public := resolver.Resolve(ctx.Request)
+		ctx.Request = ctx.Request.WithContext(links.WithPublic(ctx.Request.Context(), public))
+		ctx.Next()
+	}
+}
+
+// LimitBody returns middleware that caps request bodies at maxBytes. Reading
+// past the cap fails with an error that BodyTooLarge recognises.
+func LimitBody(maxBytes int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Body != nil {
+			ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+		}
+		ctx.Next()
+	}
+}
+
+// BodyTooLarge reports whether err came from reading a request body past its cap
+func BodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// AbsoluteURL builds an absolute public URL for an application path
+func AbsoluteURL(ctx *gin.Context, path string) string {
+	return links.Absolute(ctx.Request.Context(), path)
+}
@@ -0,0 +1,217 @@
+// Package archive exports pages to zip files and imports them back.
+//
+// An archive holds one HTML file per page under pages/ and a manifest.json
+// describing every page.
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharer/internal/modules/category"
+	"sharer/internal/modules/page"
+)
+
+// manifestName is the name of the manifest file inside an archive
+const manifestName = "manifest.json"
+
+// Version is the archive format version written to the manifest
+const Version = 1
+
+// Manifest describes the contents of an archive
+type Manifest struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Pages      []*ManifestPage `json:"pages"`
+}
+
+// ManifestPage describes a page in an archive and the file holding its content
+type ManifestPage struct {
+	*page.PageExport
+	File string `json:"file"`
+}
+
+// ImportResult summarises an import
+type ImportResult struct {
+	Imported          int
+	Renamed           int
+	CategoriesCreated int
+	Errors            []string
+}
+
+// Export writes every page that has not been deleted to w as a zip archive
+func Export(ctx context.Context, w io.Writer, pages page.Service) (int, error) {
+	exports, err := pages.ExportPages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := &Manifest{Version: Version, ExportedAt: time.Now().UTC(), Pages: make([]*ManifestPage, len(exports))}
+
+	for i, export := range exports {
+		entry := &ManifestPage{PageExport: export, File: path.Join("pages", export.Slug+".html")}
+		manifest.Pages[i] = entry
+
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: entry.File, Method: zip.Deflate, Modified: export.UpdatedAt})
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.WriteString(fw, export.HTMLContent); err != nil {
+			return 0, err
+		}
+	}
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: manifestName, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return 0, err
+	}
+	encoder := json.NewEncoder(fw)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return len(exports), nil
+}
+
+// Import creates pages from a zip archive, creating missing categories by name.
+// A page whose slug is already taken is imported under a new slug. Users are not
+// part of an archive, so a page is owned again only if its owner's username exists.
+func Import(ctx context.Context, r io.ReaderAt, size int64, pages page.Service, categories category.Service) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifestFile, ok := files[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", manifestName)
+	}
+
+	var manifest Manifest
+	if err := readJSON(manifestFile, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+
+	result := &ImportResult{}
+	categoryIDs := make(map[string]uint)
+
+	for _, entry := range manifest.Pages {
+		if entry.PageExport == nil {
+			continue
+		}
+
+		f, ok := files[entry.File]
+		if !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: missing file %s", entry.Slug, entry.File))
+			continue
+		}
+		content, err := readFile(f)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Slug, err))
+			continue
+		}
+		entry.HTMLContent = content
+
+		var categoryID *uint
+		if entry.CategoryName != nil && *entry.CategoryName != "" {
+			id, created, err := resolveCategory(ctx, categories, categoryIDs, *entry.CategoryName)
+			if err != nil {
+				return result, err
+			}
+			if created {
+				result.CategoriesCreated++
+			}
+			categoryID = &id
+		}
+
+		response, err := pages.ImportPage(ctx, entry.PageExport, categoryID)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", entry.Slug, err)
+		}
+		if response.Error != "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", entry.Slug, response.Error))
+			continue
+		}
+
+		result.Imported++
+		if response.Slug != entry.Slug {
+			result.Renamed++
+		}
+	}
+
+	return result, nil
+}
+
+// resolveCategory returns the ID of the named category, creating it when it does not exist
+func resolveCategory(ctx context.Context, categories category.Service, cache map[string]uint, name string) (uint, bool, error) {
+	if id, ok := cache[name]; ok {
+		return id, false, nil
+	}
+
+	existing, err := categories.GetCategoryByName(ctx, name)
+	if err == nil {
+		cache[name] = existing.ID
+		return existing.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+
+	response, err := categories.CreateCategory(ctx, &category.CategoryCreate{Name: name})
+	if err != nil {
+		return 0, false, err
+	}
+	if response.Error != "" {
+		return 0, false, fmt.Errorf("category %q: %s", name, response.Error)
+	}
+
+	cache[name] = response.Category.ID
+	return response.Category.ID, true, nil
+}
+
+// readFile reads a file from an archive as a string
+func readFile(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// readJSON decodes a JSON file from an archive
+func readJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return json.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,71 @@
+// Package auth carries the identity of the signed-in user through a request.
+//
+// The user module resolves the session cookie or API token and records who is
+// signed in; other modules read the identity from the request context without
+// depending on the user module itself.
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Scopes an API token can be granted
+const (
+	// ScopeRead allows reading pages and categories
+	ScopeRead = "read"
+	// ScopeWrite allows creating, changing and deleting pages and categories
+	ScopeWrite = "write"
+	// ScopeAdmin allows everything, including administration
+	ScopeAdmin = "admin"
+)
+
+// Roles a user can have
+const (
+	// RoleMember is the role of every new account
+	RoleMember = "member"
+	// RoleAdmin allows moderating pages and users and managing categories
+	RoleAdmin = "admin"
+)
+
+// Scopes lists every scope, in the order they are offered
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// Identity describes the user a request is made on behalf of
+type Identity struct {
+	UserID   uint
+	Username string
+	// Role is the user's role, such as RoleAdmin
+	Role string
+	// TokenID is the API token the request was authenticated with, zero for browser sessions
+	TokenID uint
+	// Scopes limits what an API token may do; browser sessions are not limited
+	Scopes []string
+}
+
+// Allows reports whether the identity has been granted a scope. The admin scope grants every other scope.
+func (i *Identity) Allows(scope string) bool {
+	if i.TokenID == 0 {
+		return true
+	}
+	return slices.Contains(i.Scopes, scope) || slices.Contains(i.Scopes, ScopeAdmin)
+}
+
+// IsAdmin reports whether the identity may administer the site: the user has
+// the admin role and, for API tokens, the token has the admin scope
+func (i *Identity) IsAdmin() bool {
+	return i.Role == RoleAdmin && i.Allows(ScopeAdmin)
+}
+
+type contextKey struct{}
+
+// WithIdentity returns a context carrying the signed-in user
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the signed-in user, or nil for anonymous requests
+func FromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(contextKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,592 @@
+// Package config loads application settings.
+//
+// Settings are layered: built-in defaults, then a YAML or TOML file, then
+// SHARER_* environment variables, then command-line flags. The result is
+// validated before use.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm/logger"
+
+	"sharer/internal/links"
+)
+
+// Config holds all application settings
+type Config struct {
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Uploads  UploadConfig   `yaml:"uploads" toml:"uploads"`
+	Pages    PageConfig     `yaml:"pages" toml:"pages"`
+	Features FeatureConfig  `yaml:"features" toml:"features"`
+	Limits   LimitConfig    `yaml:"rate_limits" toml:"rate_limits"`
+	Accounts AccountConfig  `yaml:"accounts" toml:"accounts"`
+	Scanner  ScannerConfig  `yaml:"scanner" toml:"scanner"`
+}
+
+// ServerConfig holds HTTP server settings
+type ServerConfig struct {
+	// Addr is the address the server listens on, such as ":8080"
+	Addr string `yaml:"addr" toml:"addr"`
+	// BaseURL is the public URL the server is reached at, used for absolute links.
+	// A path such as /sharer means a reverse proxy serves the application under that prefix.
+	BaseURL string `yaml:"base_url" toml:"base_url"`
+	// TrustedProxies are the IP addresses or CIDR ranges of reverse proxies whose
+	// X-Forwarded-* headers are honoured
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	// Mode is the Gin mode: release, debug or test
+	Mode string `yaml:"mode" toml:"mode"`
+	// LogLevel is the lowest level of server log lines written: debug, info, warn or error
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+	// LogFormat is the format of server log lines: json or text
+	LogFormat string `yaml:"log_format" toml:"log_format"`
+	// ReadTimeout limits how long reading a whole request, including an upload, may take
+	ReadTimeout Duration `yaml:"read_timeout" toml:"read_timeout"`
+	// WriteTimeout limits how long writing a response may take
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+	// IdleTimeout limits how long an idle keep-alive connection stays open
+	IdleTimeout Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownTimeout limits how long in-flight requests and workers may take to finish on shutdown
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// DatabaseConfig holds SQLite settings
+type DatabaseConfig struct {
+	// Path is the SQLite database file
+	Path string `yaml:"path" toml:"path"`
+	// Pragmas are SQLite pragmas applied to every connection, such as journal_mode or busy_timeout
+	Pragmas map[string]string `yaml:"pragmas" toml:"pragmas"`
+	// LogLevel is the level of queries logged: silent, error, warn or info
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+	// MinFreeBytes is the free disk space below which the server reports itself not ready
+	MinFreeBytes int64 `yaml:"min_free_bytes" toml:"min_free_bytes"`
+}
+
+// UploadConfig holds upload limits
+type UploadConfig struct {
+	// MaxBytes is the largest page content accepted, in bytes
+	MaxBytes int64 `yaml:"max_bytes" toml:"max_bytes"`
+	// MaxBodyBytes is the largest request body read, in bytes. It leaves room
+	// over MaxBytes for form encoding, JSON escaping and other fields.
+	MaxBodyBytes int64 `yaml:"max_body_bytes" toml:"max_body_bytes"`
+}
+
+// PageConfig holds page settings
+type PageConfig struct {
+	// SlugLength is the length of generated slugs
+	SlugLength int `yaml:"slug_length" toml:"slug_length"`
+	// ReapInterval is how often expired pages are moved to the trash, or 0 to never
+	ReapInterval Duration `yaml:"reap_interval" toml:"reap_interval"`
+}
+
+// AccountConfig holds user account settings
+type AccountConfig struct {
+	// Registration allows visitors to create accounts
+	Registration bool `yaml:"registration" toml:"registration"`
+	// SessionLifetime is how long a sign-in lasts
+	SessionLifetime Duration `yaml:"session_lifetime" toml:"session_lifetime"`
+}
+
+// ScannerConfig holds content scanner settings
+type ScannerConfig struct {
+	// Enabled scans new and updated content for phishing and malware
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Rules is a YAML or TOML file tuning the scanner's detectors and thresholds; the built-in rules apply when empty
+	Rules string `yaml:"rules" toml:"rules"`
+}
+
+// LimitConfig holds per-client rate limits for each group of routes
+type LimitConfig struct {
+	// Form limits web form submissions: page creation through POST /, sign-up and sign-in
+	Form RateLimit `yaml:"form" toml:"form"`
+	// API limits page creation through /api/share, /api/v1/pages and /api/raw
+	API RateLimit `yaml:"api" toml:"api"`
+	// View limits reads of /shared/:slug, including password attempts
+	View RateLimit `yaml:"view" toml:"view"`
+}
+
+// RateLimit is a token-bucket limit; a zero rate or burst disables it
+type RateLimit struct {
+	// PerMinute is the sustained number of requests a client may make per minute
+	PerMinute float64 `yaml:"per_minute" toml:"per_minute"`
+	// Burst is the number of requests a client may make at once after a quiet period
+	Burst int `yaml:"burst" toml:"burst"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" in config files
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// FeatureConfig holds feature toggles
+type FeatureConfig struct {
+	// API enables the JSON API under /api/v1 and /api/share
+	API bool `yaml:"api" toml:"api"`
+	// RawUpload enables the raw-body upload endpoints under /api/raw
+	RawUpload bool `yaml:"raw_upload" toml:"raw_upload"`
+	// LiveReload enables live pages that reload open viewers when updated
+	LiveReload bool `yaml:"live_reload" toml:"live_reload"`
+	// Passwords enables password-protected pages
+	Passwords bool `yaml:"passwords" toml:"passwords"`
+	// Metrics enables the Prometheus metrics endpoint at /metrics
+	Metrics bool `yaml:"metrics" toml:"metrics"`
+	// ReportBanner adds a link to the report form to every shared page; the form works either way
+	ReportBanner bool `yaml:"report_banner" toml:"report_banner"`
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			Mode:            "release",
+			LogLevel:        "info",
+			LogFormat:       "json",
+			ReadTimeout:     Duration(time.Minute),
+			WriteTimeout:    Duration(time.Minute),
+			IdleTimeout:     Duration(2 * time.Minute),
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Path:         "./sharer.db",
+			Pragmas:      map[string]string{"busy_timeout": "5000"},
+			LogLevel:     "silent",
+			MinFreeBytes: 100 << 20,
+		},
+		Uploads: UploadConfig{
+			MaxBytes:     10 << 20,
+			MaxBodyBytes: 32 << 20,
+		},
+		Pages: PageConfig{
+			SlugLength:   8,
+			ReapInterval: Duration(10 * time.Minute),
+		},
+		Features: FeatureConfig{
+			API:        true,
+			RawUpload:  true,
+			LiveReload: true,
+			Passwords:  true,
+			Metrics:    true,
+		},
+		Accounts: AccountConfig{
+			Registration:    true,
+			SessionLifetime: Duration(30 * 24 * time.Hour),
+		},
+		Scanner: ScannerConfig{
+			Enabled: true,
+		},
+		Limits: LimitConfig{
+			Form: RateLimit{PerMinute: 10, Burst: 20},
+			API:  RateLimit{PerMinute: 30, Burst: 60},
+			View: RateLimit{PerMinute: 300, Burst: 100},
+		},
+	}
+}
+
+// setting is a configuration value that can be set from the environment or a flag
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"addr", "SHARER_ADDR", "listen address", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"base-url", "SHARER_BASE_URL", "public base URL used for absolute links", func(c *Config, v string) error {
+		c.Server.BaseURL = v
+		return nil
+	}},
+	{"trusted-proxies", "SHARER_TRUSTED_PROXIES", "reverse proxy addresses or CIDR ranges separated by commas", func(c *Config, v string) error {
+		c.Server.TrustedProxies = nil
+		for _, proxy := range strings.Split(v, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				c.Server.TrustedProxies = append(c.Server.TrustedProxies, proxy)
+			}
+		}
+		return nil
+	}},
+	{"mode", "SHARER_MODE", "Gin mode: release, debug or test", func(c *Config, v string) error {
+		c.Server.Mode = v
+		return nil
+	}},
+	{"log-level", "SHARER_LOG_LEVEL", "server log level: debug, info, warn or error", func(c *Config, v string) error {
+		c.Server.LogLevel = v
+		return nil
+	}},
+	{"log-format", "SHARER_LOG_FORMAT", "server log format: json or text", func(c *Config, v string) error {
+		c.Server.LogFormat = v
+		return nil
+	}},
+	{"read-timeout", "SHARER_READ_TIMEOUT", "maximum time to read a request", durationSetting(func(c *Config) *Duration { return &c.Server.ReadTimeout })},
+	{"write-timeout", "SHARER_WRITE_TIMEOUT", "maximum time to write a response", durationSetting(func(c *Config) *Duration { return &c.Server.WriteTimeout })},
+	{"idle-timeout", "SHARER_IDLE_TIMEOUT", "maximum time an idle connection stays open", durationSetting(func(c *Config) *Duration { return &c.Server.IdleTimeout })},
+	{"shutdown-timeout", "SHARER_SHUTDOWN_TIMEOUT", "maximum time to finish requests and workers on shutdown", durationSetting(func(c *Config) *Duration { return &c.Server.ShutdownTimeout })},
+	{"db", "SHARER_DB_PATH", "SQLite database file", func(c *Config, v string) error {
+		c.Database.Path = v
+		return nil
+	}},
+	{"db-pragmas", "SHARER_DB_PRAGMAS", "SQLite pragmas as name=value pairs separated by commas", func(c *Config, v string) error {
+		pragmas, err := parsePragmas(v)
+		if err != nil {
+			return err
+		}
+		for name, value := range pragmas {
+			if c.Database.Pragmas == nil {
+				c.Database.Pragmas = make(map[string]string)
+			}
+			c.Database.Pragmas[name] = value
+		}
+		return nil
+	}},
+	{"db-log-level", "SHARER_DB_LOG_LEVEL", "database log level: silent, error, warn or info", func(c *Config, v string) error {
+		c.Database.LogLevel = v
+		return nil
+	}},
+	{"db-min-free-bytes", "SHARER_DB_MIN_FREE_BYTES", "free disk space below which the server is not ready, in bytes", func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		c.Database.MinFreeBytes = n
+		return err
+	}},
+	{"max-upload-bytes", "SHARER_MAX_UPLOAD_BYTES", "largest page content accepted, in bytes", func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		c.Uploads.MaxBytes = n
+		return err
+	}},
+	{"max-body-bytes", "SHARER_MAX_BODY_BYTES", "largest request body read, in bytes", func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		c.Uploads.MaxBodyBytes = n
+		return err
+	}},
+	{"slug-length", "SHARER_SLUG_LENGTH", "length of generated slugs", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Pages.SlugLength = n
+		return err
+	}},
+	{"reap-interval", "SHARER_REAP_INTERVAL", "how often expired pages are moved to the trash, 0 to never", durationSetting(func(c *Config) *Duration { return &c.Pages.ReapInterval })},
+	{"feature-api", "SHARER_FEATURE_API", "enable the JSON API", boolSetting(func(c *Config) *bool { return &c.Features.API })},
+	{"feature-raw-upload", "SHARER_FEATURE_RAW_UPLOAD", "enable raw-body uploads", boolSetting(func(c *Config) *bool { return &c.Features.RawUpload })},
+	{"feature-live-reload", "SHARER_FEATURE_LIVE_RELOAD", "enable live reloading pages", boolSetting(func(c *Config) *bool { return &c.Features.LiveReload })},
+	{"feature-passwords", "SHARER_FEATURE_PASSWORDS", "enable password-protected pages", boolSetting(func(c *Config) *bool { return &c.Features.Passwords })},
+	{"feature-metrics", "SHARER_FEATURE_METRICS", "enable the Prometheus metrics endpoint", boolSetting(func(c *Config) *bool { return &c.Features.Metrics })},
+	{"feature-report-banner", "SHARER_FEATURE_REPORT_BANNER", "link shared pages to their report form", boolSetting(func(c *Config) *bool { return &c.Features.ReportBanner })},
+	{"registration", "SHARER_REGISTRATION", "allow visitors to create accounts", boolSetting(func(c *Config) *bool { return &c.Accounts.Registration })},
+	{"session-lifetime", "SHARER_SESSION_LIFETIME", "how long a sign-in lasts", durationSetting(func(c *Config) *Duration { return &c.Accounts.SessionLifetime })},
+	{"scanner", "SHARER_SCANNER", "scan content for phishing and malware", boolSetting(func(c *Config) *bool { return &c.Scanner.Enabled })},
+	{"scanner-rules", "SHARER_SCANNER_RULES", "YAML or TOML file of content scanner rules", func(c *Config, v string) error {
+		c.Scanner.Rules = v
+		return nil
+	}},
+	{"rate-form-per-minute", "SHARER_RATE_FORM_PER_MINUTE", "web form page creations per client per minute, 0 for no limit", rateSetting(func(c *Config) *RateLimit { return &c.Limits.Form }, false)},
+	{"rate-form-burst", "SHARER_RATE_FORM_BURST", "web form page creations a client may burst", rateSetting(func(c *Config) *RateLimit { return &c.Limits.Form }, true)},
+	{"rate-api-per-minute", "SHARER_RATE_API_PER_MINUTE", "API page creations per client per minute, 0 for no limit", rateSetting(func(c *Config) *RateLimit { return &c.Limits.API }, false)},
+	{"rate-api-burst", "SHARER_RATE_API_BURST", "API page creations a client may burst", rateSetting(func(c *Config) *RateLimit { return &c.Limits.API }, true)},
+	{"rate-view-per-minute", "SHARER_RATE_VIEW_PER_MINUTE", "shared page views per client per minute, 0 for no limit", rateSetting(func(c *Config) *RateLimit { return &c.Limits.View }, false)},
+	{"rate-view-burst", "SHARER_RATE_VIEW_BURST", "shared page views a client may burst", rateSetting(func(c *Config) *RateLimit { return &c.Limits.View }, true)},
+}
+
+// boolSetting returns a setter for a boolean field
+func boolSetting(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		*field(c) = b
+		return err
+	}
+}
+
+// durationSetting returns a setter for a duration field
+func durationSetting(field func(c *Config) *Duration) func(c *Config, value string) error {
+	return func(c *Config, v string) error {
+		return field(c).UnmarshalText([]byte(v))
+	}
+}
+
+// rateSetting returns a setter for the rate or, when burst is set, the burst of a rate limit
+func rateSetting(field func(c *Config) *RateLimit, burst bool) func(c *Config, value string) error {
+	return func(c *Config, v string) error {
+		if burst {
+			n, err := strconv.Atoi(v)
+			field(c).Burst = n
+			return err
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		field(c).PerMinute = f
+		return err
+	}
+}
+
+// parsePragmas parses "name=value,name=value"
+func parsePragmas(value string) (map[string]string, error) {
+	pragmas := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("pragma %q is not name=value", pair)
+		}
+		pragmas[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	}
+	return pragmas, nil
+}
+
+// Loader loads configuration, applying command-line flags bound to a flag set
+type Loader struct {
+	path  string
+	flags []func(c *Config) error
+}
+
+// BindFlags registers the configuration flags on a flag set. Call Load after parsing it.
+func BindFlags(fs *flag.FlagSet) *Loader {
+	l := &Loader{}
+	fs.StringVar(&l.path, "config", "", "config file, YAML or TOML (default: $SHARER_CONFIG)")
+
+	for _, s := range settings {
+		fs.Func(s.flag, s.usage, func(value string) error {
+			// Defer the flag until after the file and environment have been applied
+			l.flags = append(l.flags, func(c *Config) error {
+				if err := s.set(c, value); err != nil {
+					return fmt.Errorf("invalid -%s: %w", s.flag, err)
+				}
+				return nil
+			})
+			return nil
+		})
+	}
+
+	return l
+}
+
+// Load builds the configuration from defaults, the config file, the environment and flags
+func (l *Loader) Load() (*Config, error) {
+	c := Default()
+
+	path := l.path
+	if path == "" {
+		path = os.Getenv("SHARER_CONFIG")
+	}
+	if path != "" {
+		if err := loadFile(c, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(c, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	for _, apply := range l.flags {
+		if err := apply(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadFile merges a YAML or TOML file into the configuration, rejecting unknown keys
+func loadFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(c); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+
+	return nil
+}
+
+// pragmaValue matches pragma values that are safe to pass to the SQLite driver
+var pragmaValue = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// supportedPragmas lists the pragmas the SQLite driver accepts in its connection string
+var supportedPragmas = map[string]bool{
+	"auto_vacuum":              true,
+	"busy_timeout":             true,
+	"cache_size":               true,
+	"case_sensitive_like":      true,
+	"defer_foreign_keys":       true,
+	"foreign_keys":             true,
+	"ignore_check_constraints": true,
+	"journal_mode":             true,
+	"locking_mode":             true,
+	"query_only":               true,
+	"recursive_triggers":       true,
+	"secure_delete":            true,
+	"synchronous":              true,
+}
+
+// GormLogLevel returns the database log level as a GORM log level
+func (c *Config) GormLogLevel() logger.LogLevel {
+	switch c.Database.LogLevel {
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	case "info":
+		return logger.Info
+	default:
+		return logger.Silent
+	}
+}
+
+// Validate checks that the configuration is usable, reporting every problem found
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr %q is not a host:port address", c.Server.Addr))
+	}
+	if c.Server.BaseURL != "" {
+		u, err := url.Parse(c.Server.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("server.base_url %q must be an absolute http or https URL", c.Server.BaseURL))
+		}
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := links.ParseProxy(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies: %q is not an IP address or CIDR range", proxy))
+		}
+	}
+	switch c.Server.Mode {
+	case "release", "debug", "test":
+	default:
+		errs = append(errs, fmt.Errorf("server.mode %q must be release, debug or test", c.Server.Mode))
+	}
+	switch c.Server.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("server.log_level %q must be debug, info, warn or error", c.Server.LogLevel))
+	}
+	switch c.Server.LogFormat {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("server.log_format %q must be json or text", c.Server.LogFormat))
+	}
+
+	timeouts := []struct {
+		name  string
+		value Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", timeout.name))
+		}
+	}
+
+	if c.Database.Path == "" {
+		errs = append(errs, errors.New("database.path is required"))
+	}
+	names := make([]string, 0, len(c.Database.Pragmas))
+	for name := range c.Database.Pragmas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !supportedPragmas[name] {
+			errs = append(errs, fmt.Errorf("database.pragmas: unsupported pragma %q", name))
+		} else if !pragmaValue.MatchString(c.Database.Pragmas[name]) {
+			errs = append(errs, fmt.Errorf("database.pragmas: invalid value %q for %s", c.Database.Pragmas[name], name))
+		}
+	}
+	switch c.Database.LogLevel {
+	case "silent", "error", "warn", "info":
+	default:
+		errs = append(errs, fmt.Errorf("database.log_level %q must be silent, error, warn or info", c.Database.LogLevel))
+	}
+
+	if c.Database.MinFreeBytes < 0 {
+		errs = append(errs, fmt.Errorf("database.min_free_bytes must not be negative, got %d", c.Database.MinFreeBytes))
+	}
+
+	if c.Uploads.MaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("uploads.max_bytes must be positive, got %d", c.Uploads.MaxBytes))
+	}
+	if c.Uploads.MaxBodyBytes < c.Uploads.MaxBytes {
+		errs = append(errs, fmt.Errorf("uploads.max_body_bytes must be at least uploads.max_bytes, got %d", c.Uploads.MaxBodyBytes))
+	}
+	if c.Pages.SlugLength < 6 || c.Pages.SlugLength > 64 {
+		errs = append(errs, fmt.Errorf("pages.slug_length must be between 6 and 64, got %d", c.Pages.SlugLength))
+	}
+	if c.Pages.ReapInterval < 0 {
+		errs = append(errs, errors.New("pages.reap_interval must not be negative"))
+	}
+
+	if c.Accounts.SessionLifetime <= 0 {
+		errs = append(errs, errors.New("accounts.session_lifetime must be positive"))
+	}
+
+	limits := []struct {
+		name  string
+		value RateLimit
+	}{
+		{"rate_limits.form", c.Limits.Form},
+		{"rate_limits.api", c.Limits.API},
+		{"rate_limits.view", c.Limits.View},
+	}
+	for _, limit := range limits {
+		if limit.value.PerMinute < 0 || limit.value.Burst < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", limit.name))
+		} else if limit.value.PerMinute > 0 && limit.value.Burst == 0 {
+			errs = append(errs, fmt.Errorf("%s.burst must be at least 1 when per_minute is set", limit.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,6 +2,11 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -14,13 +19,19 @@ import (
 // Config holds database configuration
 type Config struct {
 	DSN     string
+	Pragmas map[string]string
 	LogMode logger.LogLevel
 }
 
-// NewConnection creates a new GORM database connection
+// NewConnection creates a new GORM database connection. Queries are logged
+// through the default slog logger at the configured level.
 func NewConnection(config Config) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(config.DSN), &gorm.Config{
-		Logger: logger.Default.LogMode(config.LogMode),
+	db, err := gorm.Open(sqlite.Open(config.dsn()), &gorm.Config{
+		Logger: logger.New(slog.NewLogLogger(slog.Default().Handler(), slog.LevelInfo), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  config.LogMode,
+			IgnoreRecordNotFoundError: true,
+		}),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -29,21 +40,94 @@ func NewConnection(config Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// dsn returns the connection string with pragmas passed as driver parameters,
+// so that they apply to every pooled connection
+func (c Config) dsn() string {
+	if len(c.Pragmas) == 0 {
+		return c.DSN
+	}
+
+	params := url.Values{}
+	for name, value := range c.Pragmas {
+		params.Set("_"+name, value)
+	}
+
+	separator := "?"
+	if strings.Contains(c.DSN, "?") {
+		separator = "&"
+	}
+	return c.DSN + separator + params.Encode()
+}
+
+// models lists every model whose schema is managed by migrations
+func models() []interface{} {
+	return []interface{}{
+		&category.Category{},
+		&page.Page{},
+		&page.PageTag{},
+		&page.PageReport{},
+		&user.User{},
+		&user.Session{},
+		&user.APIToken{},
+	}
+}
+
+// FilePath returns the file behind an SQLite connection string, or empty for an
+// in-memory database
+func FilePath(dsn string) string {
+	path, _, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	if path == "" || path == ":memory:" {
+		return ""
+	}
+	return path
+}
+
 // Migrate runs database migrations for all models
 func Migrate(db *gorm.DB) error {
 	// Auto-migrate all models
-	err := db.AutoMigrate(
-		&category.Category{},
-		&page.Page{},
-		&user.User{}, // Example model, not implemented
-	)
-	if err != nil {
+	if err := db.AutoMigrate(models()...); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	return nil
 }
 
+// Pending lists the tables and columns that migrations would still create,
+// as "table" or "table.column". An empty list means the schema is current.
+func Pending(db *gorm.DB) ([]string, error) {
+	var pending []string
+	migrator := db.Migrator()
+
+	for _, model := range models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model schema: %w", err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			pending = append(pending, table)
+			continue
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if !migrator.HasColumn(model, name) {
+				pending = append(pending, table+"."+name)
+			}
+		}
+	}
+
+	return pending, nil
+}
+
+// Backup writes a consistent copy of the database to path while it stays online.
+// The target file must not already exist.
+func Backup(db *gorm.DB, path string) error {
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func Close(db *gorm.DB) error {
 	sqlDB, err := db.DB()
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"sharer/internal/database"
+)
+
+// errUnsupported is returned by checks that cannot run on this platform
+var errUnsupported = errors.New("not supported on this platform")
+
+// DatabasePing checks that the database answers on its connection pool
+func DatabasePing(db *gorm.DB) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		stats := sqlDB.Stats()
+		return map[string]any{"open_connections": stats.OpenConnections, "in_use": stats.InUse}, nil
+	}
+}
+
+// Migrations checks that every table and column the models need exists
+func Migrations(db *gorm.DB) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		pending, err := database.Pending(db.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) > 0 {
+			return map[string]any{"pending": pending}, fmt.Errorf("schema is missing %s; run sharer migrate", strings.Join(pending, ", "))
+		}
+		return nil, nil
+	}
+}
+
+// Writable checks that the database file and its directory, where SQLite keeps
+// its journal, can be written. An in-memory database always passes.
+func Writable(path string) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		if path == "" {
+			return nil, nil
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, fmt.Errorf("database file is not writable: %w", err)
+		}
+		file.Close()
+
+		probe, err := os.CreateTemp(filepath.Dir(path), ".sharer-ready-*")
+		if err != nil {
+			return nil, fmt.Errorf("database directory is not writable: %w", err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+
+		return nil, nil
+	}
+}
+
+// DiskSpace checks that the filesystem holding path has at least minFree bytes available
+func DiskSpace(path string, minFree int64) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		if path == "" {
+			return nil, nil
+		}
+
+		free, err := freeBytes(filepath.Dir(path))
+		if errors.Is(err, errUnsupported) {
+			return map[string]any{"skipped": err.Error()}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]any{"free_bytes": free, "threshold_bytes": minFree}
+		if free < minFree {
+			return details, fmt.Errorf("%d bytes free, below the %d byte threshold", free, minFree)
+		}
+		return details, nil
+	}
+}
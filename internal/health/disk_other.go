@@ -0,0 +1,8 @@
+//go:build !unix
+
+package health
+
+// freeBytes is not implemented outside Unix systems
+func freeBytes(dir string) (int64, error) {
+	return 0, errUnsupported
+}
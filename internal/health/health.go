@@ -0,0 +1,117 @@
+// Package health serves liveness and readiness probes.
+//
+// Liveness only reports that the process is serving HTTP. Readiness runs a set
+// of named checks, such as reaching the database, and reports each one so that
+// an orchestrator, or the person paged by it, can see what is wrong.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Probe statuses
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusFailed      = "failed"
+)
+
+// checkTimeout bounds how long a readiness probe may take
+const checkTimeout = 5 * time.Second
+
+// Check reports whether one dependency is ready. It may return details, such as
+// measured values, which are included in the response whether or not it fails.
+type Check func(ctx context.Context) (map[string]any, error)
+
+// Response is the body of a probe response
+type Response struct {
+	Status string                  `json:"status"`
+	Checks map[string]*CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS float64        `json:"duration_ms"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// Checker runs readiness checks
+type Checker struct {
+	names  []string
+	checks map[string]Check
+}
+
+// NewChecker creates a checker with no checks
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers a named check
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// Run runs every check concurrently and reports the overall status
+func (c *Checker) Run(ctx context.Context) *Response {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]*CheckResult, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			details, err := c.checks[name](ctx)
+
+			result := &CheckResult{
+				Status:     StatusOK,
+				DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+				Details:    details,
+			}
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	response := &Response{Status: StatusOK, Checks: make(map[string]*CheckResult, len(c.names))}
+	for i, name := range c.names {
+		response.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			response.Status = StatusUnavailable
+		}
+	}
+	return response
+}
+
+// Live handles liveness probes. It does not touch any dependency, so that a
+// struggling database does not get the process restarted.
+func Live(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, &Response{Status: StatusOK})
+}
+
+// Ready handles readiness probes, answering 503 when any check fails
+func (c *Checker) Ready(ctx *gin.Context) {
+	response := c.Run(ctx.Request.Context())
+
+	status := http.StatusOK
+	if response.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(status, response)
+}
@@ -0,0 +1,92 @@
+// Package lifecycle runs background workers and stops them in an orderly way.
+//
+// Workers receive a context that is cancelled when shutdown begins; Shutdown
+// then waits for every worker to return, so that resources such as the
+// database can be closed safely afterwards.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Manager runs background workers until it is shut down
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New creates a manager with no running workers
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{ctx: ctx, cancel: cancel}
+}
+
+// Go starts a worker. The worker must return promptly once its context is cancelled.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := fn(m.ctx); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("worker failed", "worker", name, "error", err)
+			m.mu.Lock()
+			m.errs = append(m.errs, fmt.Errorf("%s: %w", name, err))
+			m.mu.Unlock()
+		}
+	}()
+}
+
+// Every starts a worker that runs fn at a fixed interval until shutdown.
+// Errors from individual runs are logged and do not stop the worker.
+func (m *Manager) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	m.Go(name, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := fn(ctx); err != nil && ctx.Err() == nil {
+					slog.Error("worker failed", "worker", name, "error", err)
+				}
+			}
+		}
+	})
+}
+
+// Done returns a channel that is closed when shutdown begins
+func (m *Manager) Done() <-chan struct{} {
+	return m.ctx.Done()
+}
+
+// Shutdown cancels every worker and waits for them to return or for ctx to expire.
+// It returns the errors workers failed with, or ctx's error if they did not finish in time.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("workers did not stop: %w", ctx.Err())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return errors.Join(m.errs...)
+}
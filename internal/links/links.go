@@ -0,0 +1,151 @@
+// Package links builds the URLs the application emits.
+//
+// The public origin and path prefix of each request are resolved once by
+// middleware and carried in the request context, so that handlers and
+// templates produce links that work behind a reverse proxy mounted under a
+// sub-path such as /sharer/.
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Public describes where the application is reached from outside
+type Public struct {
+	// Origin is the scheme and host, such as "https://example.com"
+	Origin string
+	// Prefix is the path the application is mounted under, such as "/sharer", or empty
+	Prefix string
+}
+
+type contextKey struct{}
+
+// WithPublic returns a context carrying the public location of the application
+func WithPublic(ctx context.Context, public Public) context.Context {
+	return context.WithValue(ctx, contextKey{}, public)
+}
+
+// FromContext returns the public location carried by a context
+func FromContext(ctx context.Context) Public {
+	public, _ := ctx.Value(contextKey{}).(Public)
+	return public
+}
+
+// Path returns an application path with the public prefix, for links within the site
+func Path(ctx context.Context, path string) string {
+	return FromContext(ctx).Prefix + path
+}
+
+// Absolute returns an absolute URL for an application path, for links shared outside the site
+func Absolute(ctx context.Context, path string) string {
+	public := FromContext(ctx)
+	return public.Origin + public.Prefix + path
+}
+
+// Resolver works out the public location of requests
+type Resolver struct {
+	base    *Public
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a resolver. A non-empty baseURL is used for every request;
+// otherwise X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix are
+// honoured only from the trusted proxies, given as IP addresses or CIDR ranges.
+func NewResolver(baseURL string, trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{}
+
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		r.base = &Public{Origin: u.Scheme + "://" + u.Host, Prefix: cleanPrefix(u.Path)}
+	}
+
+	for _, proxy := range trustedProxies {
+		prefix, err := ParseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+
+	return r, nil
+}
+
+// ParseProxy parses a trusted proxy given as an IP address or CIDR range
+func ParseProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		return netip.ParsePrefix(proxy)
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Resolve returns the public location of a request
+func (r *Resolver) Resolve(req *http.Request) Public {
+	if r.base != nil {
+		return *r.base
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	host := req.Host
+	prefix := ""
+
+	if r.fromTrustedProxy(req) {
+		if proto := firstValue(req.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := firstValue(req.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+		prefix = cleanPrefix(firstValue(req.Header.Get("X-Forwarded-Prefix")))
+	}
+
+	return Public{Origin: scheme + "://" + host, Prefix: prefix}
+}
+
+// fromTrustedProxy reports whether a request arrived directly from a trusted proxy
+func (r *Resolver) fromTrustedProxy(req *http.Request) bool {
+	if len(r.trusted) == 0 {
+		return false
+	}
+
+	addrPort, err := netip.ParseAddrPort(req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstValue returns the first entry of a comma-separated header added by a chain of proxies
+func firstValue(header string) string {
+	value, _, _ := strings.Cut(header, ",")
+	return strings.TrimSpace(value)
+}
+
+// cleanPrefix normalises a path prefix to "/name" form, or empty for the root
+func cleanPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" || strings.ContainsAny(prefix, "\"'<>\\ ") {
+		return ""
+	}
+	return "/" + prefix
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// startKey holds the time a statement started in the GORM instance settings
+const startKey = "metrics:start"
+
+// GormPlugin times every database statement GORM runs
+type GormPlugin struct{}
+
+// Name returns the plugin name
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers timing callbacks around each kind of statement
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	type register func(name string, fn func(*gorm.DB)) error
+
+	callbacks := []struct {
+		operation     string
+		before, after register
+	}{
+		{"create", db.Callback().Create().Before("*").Register, db.Callback().Create().After("*").Register},
+		{"query", db.Callback().Query().Before("*").Register, db.Callback().Query().After("*").Register},
+		{"update", db.Callback().Update().Before("*").Register, db.Callback().Update().After("*").Register},
+		{"delete", db.Callback().Delete().Before("*").Register, db.Callback().Delete().After("*").Register},
+		{"row", db.Callback().Row().Before("*").Register, db.Callback().Row().After("*").Register},
+		{"raw", db.Callback().Raw().Before("*").Register, db.Callback().Raw().After("*").Register},
+	}
+
+	for _, cb := range callbacks {
+		operation := cb.operation
+		if err := cb.before("metrics:before_"+operation, startTimer); err != nil {
+			return err
+		}
+		if err := cb.after("metrics:after_"+operation, func(tx *gorm.DB) {
+			observeQuery(tx, operation)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startTimer records when a statement started
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(startKey, time.Now())
+}
+
+// observeQuery records how long a statement took
+func observeQuery(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	if start, ok := value.(time.Time); ok {
+		dbDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,122 @@
+// Package metrics exposes Prometheus metrics for the server.
+//
+// Collectors are registered on a private registry rather than the global one
+// so that only sharer's own metrics, plus the standard Go and process
+// collectors, appear on /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Page creation sources
+const (
+	SourceForm = "form"
+	SourceFile = "file"
+	SourceAPI  = "api"
+)
+
+// unmatchedRoute labels requests that did not match any route, keeping label cardinality bounded
+const unmatchedRoute = "unmatched"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sharer_http_requests_total",
+		Help: "HTTP requests handled, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sharer_http_request_duration_seconds",
+		Help:    "HTTP request latency, by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// PagesCreated counts pages created, by source: form, file or api
+	PagesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sharer_pages_created_total",
+		Help: "Pages created, by source.",
+	}, []string{"source"})
+
+	// PageViews counts shared pages served to viewers
+	PageViews = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sharer_page_views_total",
+		Help: "Shared pages served to viewers.",
+	})
+
+	// SlugCollisions counts generated slugs that were already taken and had to be retried
+	SlugCollisions = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sharer_slug_collisions_total",
+		Help: "Generated slugs that collided with an existing page and were retried.",
+	})
+
+	// RateLimited counts requests refused by a rate limiter, by limiter name
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sharer_rate_limited_total",
+		Help: "Requests refused for exceeding a rate limit, by limit.",
+	}, []string{"limit"})
+
+	// ContentScans counts page content run past the content scanner, by verdict
+	ContentScans = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sharer_content_scans_total",
+		Help: "Page content scanned for phishing and malware, by verdict.",
+	}, []string{"verdict"})
+
+	// ContentBytes is the total size of stored page content. Computing it reads
+	// every page, so it is refreshed in the background rather than on each scrape.
+	ContentBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sharer_content_bytes",
+		Help: "Total size of stored page content in bytes.",
+	})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sharer_db_query_duration_seconds",
+		Help:    "Database query latency, by operation.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		PagesCreated,
+		PageViews,
+		SlugCollisions,
+		RateLimited,
+		ContentScans,
+		ContentBytes,
+		dbDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of each request by route template
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := ctx.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
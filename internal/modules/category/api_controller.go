@@ -0,0 +1,185 @@
+package category
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/links"
+)
+
+// APICreate handles JSON API requests for creating categories
+func (c *Controller) APICreate(ctx *gin.Context) {
+	var req CategoryCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid JSON body: name is required")
+		return
+	}
+
+	response, err := c.service.CreateCategory(ctx.Request.Context(), &req)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Error creating category")
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithError(ctx, http.StatusUnprocessableEntity, api.CodeValidation, response.Error)
+		return
+	}
+
+	ctx.Header("Location", links.Path(ctx.Request.Context(), "/api/v1/categories/"+strconv.FormatUint(uint64(response.Category.ID), 10)))
+	ctx.JSON(http.StatusCreated, response.Category)
+}
+
+// APIList handles JSON API requests for listing categories
+func (c *Controller) APIList(ctx *gin.Context) {
+	page, pageSize := api.ParsePagination(ctx)
+	sort := CategorySort(ctx.DefaultQuery("sort", string(CategorySortName)))
+
+	categoriesList, total, err := c.service.GetCategoriesList(ctx.Request.Context(), page, pageSize, sort)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Error listing categories")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.ListResponse{
+		Data:       categoriesList,
+		Pagination: api.NewPagination(page, pageSize, total),
+	})
+}
+
+// APIShow handles JSON API requests for a single category
+func (c *Controller) APIShow(ctx *gin.Context) {
+	id, ok := c.apiCategoryID(ctx)
+	if !ok {
+		return
+	}
+
+	category, err := c.service.GetCategoryByID(ctx.Request.Context(), id)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, category)
+}
+
+// APILookup handles JSON API requests for looking up a category by name
+func (c *Controller) APILookup(ctx *gin.Context) {
+	name := ctx.Query("name")
+	if name == "" {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Missing name parameter")
+		return
+	}
+
+	category, err := c.service.GetCategoryByName(ctx.Request.Context(), name)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, category)
+}
+
+// APIUpdate handles JSON API requests for updating categories
+func (c *Controller) APIUpdate(ctx *gin.Context) {
+	id, ok := c.apiCategoryID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CategoryUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid JSON body")
+		return
+	}
+
+	response, err := c.service.UpdateCategory(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithError(ctx, http.StatusUnprocessableEntity, api.CodeValidation, response.Error)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Category)
+}
+
+// APIDelete handles JSON API requests for deleting categories
+func (c *Controller) APIDelete(ctx *gin.Context) {
+	id, ok := c.apiCategoryID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CategoryDelete
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid delete parameters")
+		return
+	}
+
+	response, err := c.service.DeleteCategory(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithError(ctx, http.StatusConflict, api.CodeConflict, response.Error)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// APIMerge handles JSON API requests for merging a category into another one
+func (c *Controller) APIMerge(ctx *gin.Context) {
+	id, ok := c.apiCategoryID(ctx)
+	if !ok {
+		return
+	}
+
+	var req CategoryMerge
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid JSON body: target_id is required")
+		return
+	}
+
+	response, err := c.service.MergeCategory(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithError(ctx, http.StatusUnprocessableEntity, api.CodeValidation, response.Error)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// apiCategoryID parses the category ID path parameter, writing an error response when it is invalid
+func (c *Controller) apiCategoryID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid category ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// apiError maps service errors to JSON error responses
+func (c *Controller) apiError(ctx *gin.Context, err error) {
+	if err == gorm.ErrRecordNotFound {
+		api.AbortWithError(ctx, http.StatusNotFound, api.CodeNotFound, "Category not found")
+		return
+	}
+	api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Internal server error")
+}
@@ -1,13 +1,13 @@
 package category
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"sharer/internal/links"
 	"sharer/views/components"
 	"sharer/views/pages"
 )
@@ -38,7 +38,9 @@ func (c *Controller) Index(ctx *gin.Context) {
 		}
 	}
 
-	categoriesList, total, err := c.service.GetCategoriesList(ctx.Request.Context(), page, pageSize)
+	sort := CategorySort(ctx.DefaultQuery("sort", string(CategorySortName)))
+
+	categoriesList, total, err := c.service.GetCategoriesList(ctx.Request.Context(), page, pageSize, sort)
 	if err != nil {
 		ctx.Status(http.StatusInternalServerError)
 		return
@@ -51,6 +53,8 @@ func (c *Controller) Index(ctx *gin.Context) {
 			ID:          cat.ID,
 			Name:        cat.Name,
 			Description: cat.Description,
+			PageCount:   cat.PageCount,
+			LastPageAt:  cat.LastPageAt,
 			CreatedAt:   cat.CreatedAt,
 		}
 	}
@@ -60,7 +64,7 @@ func (c *Controller) Index(ctx *gin.Context) {
 	hasPrev := page > 1
 
 	ctx.Header("Content-Type", "text/html")
-	pages.Categories(categoriesData, page, totalPages, total, hasNext, hasPrev).Render(ctx.Request.Context(), ctx.Writer)
+	pages.Categories(categoriesData, string(sort), page, totalPages, total, hasNext, hasPrev).Render(ctx.Request.Context(), ctx.Writer)
 }
 
 // Create handles category creation form display
@@ -95,7 +99,7 @@ func (c *Controller) Store(ctx *gin.Context) {
 		ctx.Header("HX-Trigger", "closeModal")
 		ctx.String(http.StatusOK, `<script>document.getElementById('create_category_modal').close(); window.location.reload();</script>`)
 	} else {
-		ctx.Redirect(http.StatusSeeOther, "/categories")
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/categories"))
 	}
 }
 
@@ -119,7 +123,7 @@ func (c *Controller) Show(ctx *gin.Context) {
 	}
 
 	// For now, redirect to categories list since we don't have a show template
-	ctx.Redirect(http.StatusSeeOther, "/categories")
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/categories"))
 }
 
 // Edit handles category edit form display
@@ -201,10 +205,58 @@ func (c *Controller) Update(ctx *gin.Context) {
 		ctx.Header("HX-Trigger", "closeModal")
 		ctx.String(http.StatusOK, `<script>document.getElementById('edit_category_modal').close(); window.location.reload();</script>`)
 	} else {
-		ctx.Redirect(http.StatusSeeOther, "/categories")
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/categories"))
 	}
 }
 
+// DeleteModal handles category delete confirmation modal content display
+func (c *Controller) DeleteModal(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := c.service.GetCategoryByID(ctx.Request.Context(), uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	pageCount, err := c.service.CountCategoryPages(ctx.Request.Context(), category.ID)
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.GetAllCategories(ctx.Request.Context())
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Offer every other category as a reassignment target
+	targets := make([]*components.CategoryOption, 0, len(categories))
+	for _, cat := range categories {
+		if cat.ID != category.ID {
+			targets = append(targets, &components.CategoryOption{ID: cat.ID, Name: cat.Name})
+		}
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	components.CategoryDeleteModalContent(&components.CategoryDeleteData{
+		ID:        category.ID,
+		Name:      category.Name,
+		PageCount: pageCount,
+		Targets:   targets,
+	}).Render(ctx.Request.Context(), ctx.Writer)
+}
+
 // Delete handles category deletion
 func (c *Controller) Delete(ctx *gin.Context) {
 	idStr := ctx.Param("id")
@@ -214,7 +266,13 @@ func (c *Controller) Delete(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.DeleteCategory(ctx.Request.Context(), uint(id))
+	var req CategoryDelete
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	response, err := c.service.DeleteCategory(ctx.Request.Context(), uint(id), &req)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
@@ -224,11 +282,108 @@ func (c *Controller) Delete(ctx *gin.Context) {
 		return
 	}
 
+	if response.Error != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": response.Error})
+		return
+	}
+
 	// Return success response or redirect
 	if ctx.GetHeader("HX-Request") == "true" {
-		ctx.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+		// For modal requests, return HTML that triggers modal close and page refresh
+		ctx.Header("Content-Type", "text/html")
+		ctx.Header("HX-Trigger", "closeModal")
+		ctx.String(http.StatusOK, `<script>document.getElementById('delete_category_modal').close(); window.location.reload();</script>`)
 	} else {
-		ctx.Redirect(http.StatusSeeOther, "/categories")
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/categories"))
+	}
+}
+
+// MergeModal handles category merge modal content display
+func (c *Controller) MergeModal(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := c.service.GetCategoryByID(ctx.Request.Context(), uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	pageCount, err := c.service.CountCategoryPages(ctx.Request.Context(), category.ID)
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.GetAllCategories(ctx.Request.Context())
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Offer every other category as a merge target
+	targets := make([]*components.CategoryOption, 0, len(categories))
+	for _, cat := range categories {
+		if cat.ID != category.ID {
+			targets = append(targets, &components.CategoryOption{ID: cat.ID, Name: cat.Name})
+		}
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	components.CategoryMergeModalContent(&components.CategoryMergeData{
+		ID:        category.ID,
+		Name:      category.Name,
+		PageCount: pageCount,
+		Targets:   targets,
+	}).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// Merge handles merging a category into another one
+func (c *Controller) Merge(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var req CategoryMerge
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return
+	}
+
+	response, err := c.service.MergeCategory(ctx.Request.Context(), uint(id), &req)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	if response.Error != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": response.Error})
+		return
+	}
+
+	// Return success response or redirect
+	if ctx.GetHeader("HX-Request") == "true" {
+		// For modal requests, return HTML that triggers modal close and page refresh
+		ctx.Header("Content-Type", "text/html")
+		ctx.Header("HX-Trigger", "closeModal")
+		ctx.String(http.StatusOK, `<script>document.getElementById('merge_category_modal').close(); window.location.reload();</script>`)
+	} else {
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/categories"))
 	}
 }
 
@@ -241,10 +396,11 @@ func (c *Controller) GetAllForDropdown(ctx *gin.Context) {
 	}
 
 	// Return HTML options for the dropdown
-	html := `<option value="">Select a category...</option>`
-	for _, cat := range categories {
-		html += fmt.Sprintf(`<option value="%d">%s</option>`, cat.ID, cat.Name)
+	options := make([]*components.CategoryOption, len(categories))
+	for i, cat := range categories {
+		options[i] = &components.CategoryOption{ID: cat.ID, Name: cat.Name}
 	}
 
-	ctx.Data(http.StatusOK, "text/html", []byte(html))
+	ctx.Header("Content-Type", "text/html")
+	components.CategoryOptions(options).Render(ctx.Request.Context(), ctx.Writer)
 }
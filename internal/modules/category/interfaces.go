@@ -13,8 +13,8 @@ type Repository interface {
 	// GetByName retrieves a category by its name
 	GetByName(ctx context.Context, name string) (*Category, error)
 
-	// List retrieves a paginated list of categories
-	List(ctx context.Context, offset, limit int) ([]*CategoryList, error)
+	// List retrieves a paginated list of categories with their page statistics
+	List(ctx context.Context, offset, limit int, sort CategorySort) ([]*CategoryList, error)
 
 	// GetAll retrieves all categories (for dropdowns)
 	GetAll(ctx context.Context) ([]*CategoryList, error)
@@ -30,6 +30,15 @@ type Repository interface {
 
 	// Exists checks if a category name already exists
 	Exists(ctx context.Context, name string) (bool, error)
+
+	// CountPages returns the number of live pages in a category
+	CountPages(ctx context.Context, id uint) (int64, error)
+
+	// ReassignPages moves all pages of a category to another one, or clears it when toID is nil
+	ReassignPages(ctx context.Context, fromID uint, toID *uint) (int64, error)
+
+	// WithTransaction runs fn with a repository bound to a single database transaction
+	WithTransaction(ctx context.Context, fn func(repo Repository) error) error
 }
 
 // Service defines the interface for category business logic operations
@@ -40,8 +49,11 @@ type Service interface {
 	// GetCategoryByID retrieves a category by its ID
 	GetCategoryByID(ctx context.Context, id uint) (*CategoryDetail, error)
 
-	// GetCategoriesList retrieves a paginated list of categories
-	GetCategoriesList(ctx context.Context, page, pageSize int) ([]*CategoryList, int64, error)
+	// GetCategoryByName retrieves a category by its name
+	GetCategoryByName(ctx context.Context, name string) (*CategoryDetail, error)
+
+	// GetCategoriesList retrieves a paginated list of categories with their page statistics
+	GetCategoriesList(ctx context.Context, page, pageSize int, sort CategorySort) ([]*CategoryList, int64, error)
 
 	// GetAllCategories retrieves all categories for dropdowns
 	GetAllCategories(ctx context.Context) ([]*CategoryList, error)
@@ -49,6 +61,12 @@ type Service interface {
 	// UpdateCategory updates a category
 	UpdateCategory(ctx context.Context, id uint, req *CategoryUpdate) (*CategoryResponse, error)
 
-	// DeleteCategory deletes a category
-	DeleteCategory(ctx context.Context, id uint) error
+	// CountCategoryPages returns the number of live pages in a category
+	CountCategoryPages(ctx context.Context, id uint) (int64, error)
+
+	// DeleteCategory deletes a category, handling its pages according to the delete mode
+	DeleteCategory(ctx context.Context, id uint, req *CategoryDelete) (*CategoryDeleteResponse, error)
+
+	// MergeCategory moves all pages of a category into another one and deletes it
+	MergeCategory(ctx context.Context, sourceID uint, req *CategoryMerge) (*CategoryMergeResponse, error)
 }
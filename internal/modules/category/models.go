@@ -29,12 +29,26 @@ type CategoryUpdate struct {
 
 // CategoryList represents a simplified category for listing purposes
 type CategoryList struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	PageCount   int64      `json:"page_count"`
+	LastPageAt  *time.Time `json:"last_page_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
+// CategorySort determines the order of category listings
+type CategorySort string
+
+const (
+	// CategorySortName orders categories alphabetically
+	CategorySortName CategorySort = "name"
+	// CategorySortPages orders categories by number of pages, largest first
+	CategorySortPages CategorySort = "pages"
+	// CategorySortActivity orders categories by their newest page, most recent first
+	CategorySortActivity CategorySort = "activity"
+)
+
 // CategoryDetail represents detailed category information
 type CategoryDetail struct {
 	ID          uint      `json:"id"`
@@ -44,6 +58,43 @@ type CategoryDetail struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// DeleteMode determines what happens to the pages of a deleted category
+type DeleteMode string
+
+const (
+	// DeleteModeRestrict refuses to delete a category that still has pages
+	DeleteModeRestrict DeleteMode = "restrict"
+	// DeleteModeReassign moves the pages to another category before deleting
+	DeleteModeReassign DeleteMode = "reassign"
+	// DeleteModeUnassign clears the category of the pages before deleting
+	DeleteModeUnassign DeleteMode = "unassign"
+)
+
+// CategoryDelete represents the options for deleting a category
+type CategoryDelete struct {
+	Mode             DeleteMode `json:"mode,omitempty" form:"mode"`
+	TargetCategoryID *uint      `json:"target_category_id,omitempty" form:"target_category_id"`
+}
+
+// CategoryDeleteResponse represents the API response for category deletion
+type CategoryDeleteResponse struct {
+	PagesMoved int64  `json:"pages_moved"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CategoryMerge represents the data needed to merge a category into another one
+type CategoryMerge struct {
+	TargetID            uint `json:"target_id" form:"target_id" binding:"required"`
+	CombineDescriptions bool `json:"combine_descriptions,omitempty" form:"combine_descriptions"`
+}
+
+// CategoryMergeResponse represents the API response for category merges
+type CategoryMergeResponse struct {
+	Category   *CategoryDetail `json:"category,omitempty"`
+	PagesMoved int64           `json:"pages_moved"`
+	Error      string          `json:"error,omitempty"`
+}
+
 // CategoryResponse represents the API response for category operations
 type CategoryResponse struct {
 	Category *CategoryDetail `json:"category,omitempty"`
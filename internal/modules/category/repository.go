@@ -2,9 +2,14 @@ package category
 
 import (
 	"context"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// pagesTable is the table holding shared pages, owned by the page module
+const pagesTable = "shared_content"
+
 // repository implements the Repository interface using GORM
 type repository struct {
 	db *gorm.DB
@@ -40,20 +45,61 @@ func (r *repository) GetByName(ctx context.Context, name string) (*Category, err
 	return &category, nil
 }
 
-// List retrieves a paginated list of categories
-func (r *repository) List(ctx context.Context, offset, limit int) ([]*CategoryList, error) {
-	var categories []*CategoryList
+// categoryListRow is the aggregate row scanned by List.
+// SQLite returns MAX() of a timestamp as text, so the newest page time is aggregated as a unix timestamp.
+type categoryListRow struct {
+	ID           uint
+	Name         string
+	Description  string
+	PageCount    int64
+	LastPageUnix *int64
+	CreatedAt    time.Time
+}
+
+// categorySortOrders maps each sort option to its ORDER BY clause
+var categorySortOrders = map[CategorySort]string{
+	CategorySortName:     "c.name ASC",
+	CategorySortPages:    "page_count DESC, c.name ASC",
+	CategorySortActivity: "last_page_unix DESC NULLS LAST, c.name ASC",
+}
+
+// List retrieves a paginated list of categories with their page statistics
+func (r *repository) List(ctx context.Context, offset, limit int, sort CategorySort) ([]*CategoryList, error) {
+	order, ok := categorySortOrders[sort]
+	if !ok {
+		order = categorySortOrders[CategorySortName]
+	}
+
+	var rows []*categoryListRow
 	err := r.db.WithContext(ctx).
-		Model(&Category{}).
-		Select("id, name, description, created_at").
-		Order("name ASC").
+		Table("categories c").
+		Select("c.id, c.name, c.description, c.created_at, COUNT(p.id) AS page_count, MAX(unixepoch(p.created_at)) AS last_page_unix").
+		Joins("LEFT JOIN " + pagesTable + " p ON p.category_id = c.id AND p.deleted_at IS NULL").
+		Where("c.deleted_at IS NULL").
+		Group("c.id").
+		Order(order).
 		Offset(offset).
 		Limit(limit).
-		Find(&categories).Error
+		Find(&rows).Error
 
 	if err != nil {
 		return nil, err
 	}
+
+	categories := make([]*CategoryList, len(rows))
+	for i, row := range rows {
+		categories[i] = &CategoryList{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: row.Description,
+			PageCount:   row.PageCount,
+			CreatedAt:   row.CreatedAt,
+		}
+		if row.LastPageUnix != nil {
+			lastPageAt := time.Unix(*row.LastPageUnix, 0)
+			categories[i].LastPageAt = &lastPageAt
+		}
+	}
 	return categories, nil
 }
 
@@ -111,3 +157,33 @@ func (r *repository) Exists(ctx context.Context, name string) (bool, error) {
 	}
 	return count > 0, nil
 }
+
+// CountPages returns the number of live pages in a category
+func (r *repository) CountPages(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table(pagesTable).
+		Where("category_id = ? AND deleted_at IS NULL", id).
+		Count(&count).Error
+	return count, err
+}
+
+// ReassignPages moves all pages of a category to another one, or clears it when toID is nil.
+// Soft-deleted pages are moved too so that restoring them never points at a deleted category.
+func (r *repository) ReassignPages(ctx context.Context, fromID uint, toID *uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Table(pagesTable).
+		Where("category_id = ?", fromID).
+		Updates(map[string]interface{}{
+			"category_id": toID,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// WithTransaction runs fn with a repository bound to a single database transaction
+func (r *repository) WithTransaction(ctx context.Context, fn func(repo Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&repository{db: tx})
+	})
+}
@@ -2,7 +2,12 @@ package category
 
 import (
 	"context"
+	"fmt"
 	"strings"
+
+	"gorm.io/gorm"
+
+	"sharer/internal/logging"
 )
 
 // service implements the Service interface
@@ -25,6 +30,7 @@ func (s *service) CreateCategory(ctx context.Context, req *CategoryCreate) (*Cat
 	// Check if category name already exists
 	exists, err := s.repo.Exists(ctx, req.Name)
 	if err != nil {
+		logging.FromContext(ctx).Error("checking category existence failed", "error", err)
 		return &CategoryResponse{Error: "Error checking category existence"}, err
 	}
 	if exists {
@@ -39,6 +45,7 @@ func (s *service) CreateCategory(ctx context.Context, req *CategoryCreate) (*Cat
 
 	// Save to repository
 	if err := s.repo.Create(ctx, category); err != nil {
+		logging.FromContext(ctx).Error("creating category failed", "error", err)
 		return &CategoryResponse{Error: "Error creating category"}, err
 	}
 
@@ -70,8 +77,24 @@ func (s *service) GetCategoryByID(ctx context.Context, id uint) (*CategoryDetail
 	}, nil
 }
 
-// GetCategoriesList retrieves a paginated list of categories
-func (s *service) GetCategoriesList(ctx context.Context, page, pageSize int) ([]*CategoryList, int64, error) {
+// GetCategoryByName retrieves a category by its name
+func (s *service) GetCategoryByName(ctx context.Context, name string) (*CategoryDetail, error) {
+	category, err := s.repo.GetByName(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CategoryDetail{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+		CreatedAt:   category.CreatedAt,
+		UpdatedAt:   category.UpdatedAt,
+	}, nil
+}
+
+// GetCategoriesList retrieves a paginated list of categories with their page statistics
+func (s *service) GetCategoriesList(ctx context.Context, page, pageSize int, sort CategorySort) ([]*CategoryList, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -81,7 +104,13 @@ func (s *service) GetCategoriesList(ctx context.Context, page, pageSize int) ([]
 
 	offset := (page - 1) * pageSize
 
-	categories, err := s.repo.List(ctx, offset, pageSize)
+	switch sort {
+	case CategorySortName, CategorySortPages, CategorySortActivity:
+	default:
+		sort = CategorySortName
+	}
+
+	categories, err := s.repo.List(ctx, offset, pageSize, sort)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -118,6 +147,7 @@ func (s *service) UpdateCategory(ctx context.Context, id uint, req *CategoryUpda
 		if name != category.Name {
 			exists, err := s.repo.Exists(ctx, name)
 			if err != nil {
+				logging.FromContext(ctx).Error("checking category existence failed", "error", err)
 				return &CategoryResponse{Error: "Error checking category existence"}, err
 			}
 			if exists {
@@ -134,12 +164,14 @@ func (s *service) UpdateCategory(ctx context.Context, id uint, req *CategoryUpda
 
 	// Update category
 	if err := s.repo.Update(ctx, id, req); err != nil {
+		logging.FromContext(ctx).Error("updating category failed", "error", err)
 		return &CategoryResponse{Error: "Error updating category"}, err
 	}
 
 	// Get updated category
 	updatedCategory, err := s.repo.GetByID(ctx, id)
 	if err != nil {
+		logging.FromContext(ctx).Error("retrieving updated category failed", "error", err)
 		return &CategoryResponse{Error: "Error retrieving updated category"}, err
 	}
 
@@ -154,16 +186,148 @@ func (s *service) UpdateCategory(ctx context.Context, id uint, req *CategoryUpda
 	return &CategoryResponse{Category: categoryDetail}, nil
 }
 
-// DeleteCategory deletes a category
-func (s *service) DeleteCategory(ctx context.Context, id uint) error {
+// CountCategoryPages returns the number of live pages in a category
+func (s *service) CountCategoryPages(ctx context.Context, id uint) (int64, error) {
+	return s.repo.CountPages(ctx, id)
+}
+
+// DeleteCategory deletes a category, handling its pages according to the delete mode
+func (s *service) DeleteCategory(ctx context.Context, id uint, req *CategoryDelete) (*CategoryDeleteResponse, error) {
 	// Check if category exists
 	_, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = DeleteModeRestrict
 	}
 
-	// TODO: Check if category is being used by any pages
-	// This should be implemented after updating the page module
+	var targetID *uint
+	switch mode {
+	case DeleteModeRestrict, DeleteModeUnassign:
+	case DeleteModeReassign:
+		if req.TargetCategoryID == nil || *req.TargetCategoryID == 0 {
+			return &CategoryDeleteResponse{Error: "Target category is required"}, nil
+		}
+		if *req.TargetCategoryID == id {
+			return &CategoryDeleteResponse{Error: "Target category must be different from the deleted category"}, nil
+		}
+		if _, err := s.repo.GetByID(ctx, *req.TargetCategoryID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return &CategoryDeleteResponse{Error: "Target category not found"}, nil
+			}
+			logging.FromContext(ctx).Error("checking target category failed", "error", err)
+			return &CategoryDeleteResponse{Error: "Error checking target category"}, err
+		}
+		targetID = req.TargetCategoryID
+	default:
+		return &CategoryDeleteResponse{Error: "Invalid delete mode"}, nil
+	}
+
+	response := &CategoryDeleteResponse{}
+	err = s.repo.WithTransaction(ctx, func(repo Repository) error {
+		if mode == DeleteModeRestrict {
+			count, err := repo.CountPages(ctx, id)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				response.Error = fmt.Sprintf("Category is used by %d page(s); reassign or unassign them first", count)
+				return nil
+			}
+		} else {
+			moved, err := repo.ReassignPages(ctx, id, targetID)
+			if err != nil {
+				return err
+			}
+			response.PagesMoved = moved
+		}
 
-	return s.repo.Delete(ctx, id)
+		return repo.Delete(ctx, id)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("deleting category failed", "error", err)
+		return &CategoryDeleteResponse{Error: "Error deleting category"}, err
+	}
+
+	return response, nil
+}
+
+// MergeCategory moves all pages of a category into another one and deletes it
+func (s *service) MergeCategory(ctx context.Context, sourceID uint, req *CategoryMerge) (*CategoryMergeResponse, error) {
+	// Check if source category exists
+	source, err := s.repo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TargetID == sourceID {
+		return &CategoryMergeResponse{Error: "Cannot merge a category into itself"}, nil
+	}
+
+	target, err := s.repo.GetByID(ctx, req.TargetID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &CategoryMergeResponse{Error: "Target category not found"}, nil
+		}
+		logging.FromContext(ctx).Error("checking target category failed", "error", err)
+		return &CategoryMergeResponse{Error: "Error checking target category"}, err
+	}
+
+	response := &CategoryMergeResponse{}
+	err = s.repo.WithTransaction(ctx, func(repo Repository) error {
+		moved, err := repo.ReassignPages(ctx, source.ID, &target.ID)
+		if err != nil {
+			return err
+		}
+		response.PagesMoved = moved
+
+		if req.CombineDescriptions {
+			description := combineDescriptions(target.Description, source.Description)
+			if description != target.Description {
+				if err := repo.Update(ctx, target.ID, &CategoryUpdate{Description: &description}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return repo.Delete(ctx, source.ID)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("merging categories failed", "error", err)
+		return &CategoryMergeResponse{Error: "Error merging categories"}, err
+	}
+
+	// Get merged category
+	merged, err := s.repo.GetByID(ctx, target.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("retrieving merged category failed", "error", err)
+		return &CategoryMergeResponse{Error: "Error retrieving merged category"}, err
+	}
+
+	response.Category = &CategoryDetail{
+		ID:          merged.ID,
+		Name:        merged.Name,
+		Description: merged.Description,
+		CreatedAt:   merged.CreatedAt,
+		UpdatedAt:   merged.UpdatedAt,
+	}
+
+	return response, nil
+}
+
+// combineDescriptions appends the source description to the target one, skipping empty or duplicate text
+func combineDescriptions(target, source string) string {
+	target = strings.TrimSpace(target)
+	source = strings.TrimSpace(source)
+
+	if source == "" || strings.Contains(target, source) {
+		return target
+	}
+	if target == "" {
+		return source
+	}
+	return target + "\n\n" + source
 }
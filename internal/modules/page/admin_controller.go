@@ -0,0 +1,124 @@
+package page
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/views/pages"
+)
+
+// adminPageNotices confirms moderation actions, keyed by the done query parameter
+var adminPageNotices = map[string]string{
+	"deleted":    "Page permanently deleted.",
+	"restored":   "Page restored.",
+	"reinstated": "Page reinstated.",
+}
+
+// AdminPages handles the moderation listing of all pages
+func (c *Controller) AdminPages(ctx *gin.Context) {
+	var filter AdminPageFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid filter parameters")
+		return
+	}
+
+	page, pageSize := api.ParsePagination(ctx)
+	pagesList, total, err := c.service.AdminListPages(ctx.Request.Context(), &filter, page, pageSize)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing pages for moderation failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading pages")
+		return
+	}
+
+	data := &pages.AdminPagesData{
+		Pages:      make([]pages.AdminPageData, len(pagesList)),
+		Query:      filter.Query,
+		Status:     filter.Status,
+		Page:       page,
+		TotalPages: api.NewPagination(page, pageSize, total).TotalPages,
+		Total:      total,
+		Notice:     adminPageNotices[ctx.Query("done")],
+	}
+	for i, p := range pagesList {
+		data.Pages[i] = pages.AdminPageData{
+			ID:            p.ID,
+			Slug:          p.Slug,
+			Title:         p.Title,
+			OwnerName:     p.OwnerName,
+			Size:          p.Size,
+			Views:         p.Views,
+			CreatorIP:     p.CreatorIP,
+			CreatedAt:     p.CreatedAt,
+			DeletedAt:     p.DeletedAt,
+			TakenDownAt:   p.TakenDownAt,
+			ScanScore:     p.ScanScore,
+			QuarantinedAt: p.QuarantinedAt,
+			OpenReports:   p.OpenReports,
+		}
+		if p.TakenDownAt != nil {
+			data.Pages[i].TakedownCause = p.TakedownCause.Label()
+		}
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	pages.AdminPages(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// AdminHardDelete handles permanently deleting a page
+func (c *Controller) AdminHardDelete(ctx *gin.Context) {
+	id, ok := adminPageID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.HardDeletePage(ctx.Request.Context(), id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.String(http.StatusNotFound, "Page not found")
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("hard deleting page failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error deleting page")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/pages?done=deleted"))
+}
+
+// AdminRestore handles restoring a soft-deleted page
+func (c *Controller) AdminRestore(ctx *gin.Context) {
+	id, ok := adminPageID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.RestorePage(ctx.Request.Context(), id); err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			ctx.String(http.StatusNotFound, "Page not found")
+		case ErrNotDeleted:
+			ctx.String(http.StatusConflict, "Page is not deleted")
+		default:
+			logging.FromContext(ctx.Request.Context()).Error("restoring page failed", "error", err)
+			ctx.String(http.StatusInternalServerError, "Error restoring page")
+		}
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/pages?done=restored"))
+}
+
+// adminPageID parses the page ID route parameter, writing a 400 response when it is invalid
+func adminPageID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid page ID")
+		return 0, false
+	}
+	return uint(id), true
+}
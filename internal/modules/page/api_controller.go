@@ -0,0 +1,149 @@
+package page
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/links"
+	"sharer/internal/metrics"
+)
+
+// EditTokenHeader is the request header carrying a page's edit token
+const EditTokenHeader = "X-Edit-Token"
+
+// APICreate handles JSON API requests for creating pages
+func (c *Controller) APICreate(ctx *gin.Context) {
+	var req PageCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.apiBindError(ctx, err, "Invalid JSON body: html_content is required")
+		return
+	}
+	req.CreatorIP = ctx.ClientIP()
+
+	response, err := c.service.CreatePage(ctx.Request.Context(), &req)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Error creating page")
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithReason(ctx, http.StatusUnprocessableEntity, api.CodeValidation, response.Code, response.Error)
+		return
+	}
+
+	metrics.PagesCreated.WithLabelValues(metrics.SourceAPI).Inc()
+	ctx.Header("Location", links.Path(ctx.Request.Context(), "/api/v1/pages/"+response.Slug))
+	ctx.JSON(http.StatusCreated, &PageResponse{
+		URL:         api.AbsoluteURL(ctx, response.URL),
+		Slug:        response.Slug,
+		EditToken:   response.EditToken,
+		Quarantined: response.Quarantined,
+	})
+}
+
+// APIList handles JSON API requests for listing pages
+func (c *Controller) APIList(ctx *gin.Context) {
+	var filter PageFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid filter parameters")
+		return
+	}
+
+	page, pageSize := api.ParsePagination(ctx)
+	pagesList, total, err := c.service.SearchPages(ctx.Request.Context(), &filter, page, pageSize)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Error listing pages")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.ListResponse{
+		Data:       pagesList,
+		Pagination: api.NewPagination(page, pageSize, total),
+	})
+}
+
+// APIShow handles JSON API requests for a page's metadata
+func (c *Controller) APIShow(ctx *gin.Context) {
+	metadata, err := c.service.GetPageMetadata(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, metadata)
+}
+
+// APIUpdate handles JSON API requests for updating pages
+func (c *Controller) APIUpdate(ctx *gin.Context) {
+	editToken := ctx.GetHeader(EditTokenHeader)
+	if editToken == "" && auth.FromContext(ctx.Request.Context()) == nil {
+		api.AbortWithError(ctx, http.StatusUnauthorized, api.CodeUnauthorized, "Missing "+EditTokenHeader+" header")
+		return
+	}
+
+	var req PageUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.apiBindError(ctx, err, "Invalid JSON body")
+		return
+	}
+
+	response, err := c.service.UpdatePage(ctx.Request.Context(), ctx.Param("slug"), editToken, &req)
+	if err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithReason(ctx, http.StatusUnprocessableEntity, api.CodeValidation, response.Code, response.Error)
+		return
+	}
+
+	// A quarantined page is saved but hidden until reviewed
+	if response.Quarantined {
+		ctx.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.Page)
+}
+
+// APIDelete handles JSON API requests for deleting pages
+func (c *Controller) APIDelete(ctx *gin.Context) {
+	editToken := ctx.GetHeader(EditTokenHeader)
+	if editToken == "" && auth.FromContext(ctx.Request.Context()) == nil {
+		api.AbortWithError(ctx, http.StatusUnauthorized, api.CodeUnauthorized, "Missing "+EditTokenHeader+" header")
+		return
+	}
+
+	if err := c.service.DeletePage(ctx.Request.Context(), ctx.Param("slug"), editToken); err != nil {
+		c.apiError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// apiBindError reports a request body that could not be bound, distinguishing one over the size cap
+func (c *Controller) apiBindError(ctx *gin.Context, err error, message string) {
+	if api.BodyTooLarge(err) {
+		api.AbortWithError(ctx, http.StatusRequestEntityTooLarge, api.CodePayloadTooLarge, "Request body is too large")
+		return
+	}
+	api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, message)
+}
+
+// apiError maps service errors to JSON error responses
+func (c *Controller) apiError(ctx *gin.Context, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		api.AbortWithError(ctx, http.StatusNotFound, api.CodeNotFound, "Page not found")
+	case ErrInvalidEditToken:
+		api.AbortWithError(ctx, http.StatusForbidden, api.CodeForbidden, "Invalid edit token")
+	default:
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Internal server error")
+	}
+}
@@ -1,19 +1,27 @@
 package page
 
 import (
+	"errors"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/a-h/templ"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"sharer/internal/api"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/internal/metrics"
 	"sharer/views/components"
 	"sharer/views/pages"
 )
 
+// multipartMemory is how much of a multipart form is held in memory; larger uploads spill to temporary files
+const multipartMemory = 8 << 20
+
 // Controller handles HTTP requests for page operations
 type Controller struct {
 	service Service
@@ -26,8 +34,16 @@ func NewController(service Service) *Controller {
 
 // Home handles the home page display
 func (c *Controller) Home(ctx *gin.Context) {
+	// Forms submitted without htmx are redirected here with the new page's slug
+	var result templ.Component
+	if slug := ctx.Query("success"); validSlug.MatchString(slug) {
+		result = components.Success(links.Absolute(ctx.Request.Context(), "/shared/"+slug), false)
+	} else if slug := ctx.Query("held"); validSlug.MatchString(slug) {
+		result = components.Success(links.Absolute(ctx.Request.Context(), "/shared/"+slug), true)
+	}
+
 	ctx.Header("Content-Type", "text/html")
-	pages.Home().Render(ctx.Request.Context(), ctx.Writer)
+	pages.Home(result).Render(ctx.Request.Context(), ctx.Writer)
 }
 
 // Index handles the index page showing list of shared pages
@@ -62,6 +78,7 @@ func (c *Controller) Index(ctx *gin.Context) {
 					Title:        p.Title,
 					CategoryID:   p.CategoryID,
 					CategoryName: p.CategoryName,
+					Tags:         p.Tags,
 					CreatedAt:    p.CreatedAt,
 				}
 			}
@@ -81,6 +98,7 @@ func (c *Controller) Index(ctx *gin.Context) {
 				Title:        p.Title,
 				CategoryID:   p.CategoryID,
 				CategoryName: p.CategoryName,
+				Tags:         p.Tags,
 				CreatedAt:    p.CreatedAt,
 			}
 		}
@@ -100,6 +118,7 @@ func (c *Controller) Index(ctx *gin.Context) {
 			ID:        p.ID,
 			Slug:      p.Slug,
 			Title:     p.Title,
+			Tags:      p.Tags,
 			CreatedAt: p.CreatedAt,
 		}
 	}
@@ -115,22 +134,28 @@ func (c *Controller) Index(ctx *gin.Context) {
 // CreateFromForm handles form submission for creating pages
 func (c *Controller) CreateFromForm(ctx *gin.Context) {
 	var htmlContent string
+	source := metrics.SourceForm
+
+	// Parse the whole body up front so that an oversized one is reported rather than
+	// silently read as an empty form
+	if err := ctx.Request.ParseMultipartForm(multipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		if api.BodyTooLarge(err) {
+			c.formError(ctx, http.StatusRequestEntityTooLarge, "The upload is too large")
+		} else {
+			c.formError(ctx, http.StatusBadRequest, "The form could not be read")
+		}
+		return
+	}
 
 	// Priority: textarea content over file
 	textareaContent := ctx.PostForm("htmlContent")
 	if strings.TrimSpace(textareaContent) != "" {
 		htmlContent = textareaContent
 	} else {
-		// Try to read from uploaded file
+		// Try to read from uploaded file; its type is checked by sniffing the
+		// content rather than trusting the file name
 		file, err := ctx.FormFile("htmlFile")
 		if err == nil {
-			// Validate file extension
-			ext := strings.ToLower(filepath.Ext(file.Filename))
-			if ext != ".html" && ext != ".htm" {
-				ctx.String(http.StatusBadRequest, "Please upload an HTML file")
-				return
-			}
-
 			src, err := file.Open()
 			if err != nil {
 				ctx.String(http.StatusInternalServerError, "Error reading file")
@@ -144,11 +169,12 @@ func (c *Controller) CreateFromForm(ctx *gin.Context) {
 				return
 			}
 			htmlContent = string(content)
+			source = metrics.SourceFile
 		}
 	}
 
 	if strings.TrimSpace(htmlContent) == "" {
-		ctx.String(http.StatusBadRequest, "No HTML content provided")
+		c.formError(ctx, http.StatusBadRequest, "No HTML content provided")
 		return
 	}
 
@@ -165,6 +191,7 @@ func (c *Controller) CreateFromForm(ctx *gin.Context) {
 	req := &PageCreate{
 		HTMLContent: htmlContent,
 		CategoryID:  categoryID,
+		CreatorIP:   ctx.ClientIP(),
 	}
 	response, err := c.service.CreatePage(ctx.Request.Context(), req)
 	if err != nil {
@@ -173,17 +200,22 @@ func (c *Controller) CreateFromForm(ctx *gin.Context) {
 	}
 
 	if response.Error != "" {
-		ctx.String(http.StatusBadRequest, response.Error)
+		c.formError(ctx, http.StatusBadRequest, response.Error)
 		return
 	}
+	metrics.PagesCreated.WithLabelValues(source).Inc()
 
 	// Return success component for htmx or redirect for regular form
 	if ctx.GetHeader("HX-Request") == "true" {
-		fullURL := "http://" + ctx.Request.Host + response.URL
+		fullURL := links.Absolute(ctx.Request.Context(), response.URL)
 		ctx.Header("Content-Type", "text/html")
-		components.Success(fullURL).Render(ctx.Request.Context(), ctx.Writer)
+		components.Success(fullURL, response.Quarantined).Render(ctx.Request.Context(), ctx.Writer)
 	} else {
-		ctx.Redirect(http.StatusSeeOther, "/?success="+strings.TrimPrefix(response.URL, "/shared/"))
+		outcome := "success"
+		if response.Quarantined {
+			outcome = "held"
+		}
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/?"+outcome+"="+response.Slug))
 	}
 }
 
@@ -191,25 +223,70 @@ func (c *Controller) CreateFromForm(ctx *gin.Context) {
 func (c *Controller) CreateFromAPI(ctx *gin.Context) {
 	var req PageCreate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.String(http.StatusBadRequest, "Invalid JSON")
+		if api.BodyTooLarge(err) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, &PageResponse{Error: "Request body is too large", Code: ContentTooLarge})
+		} else {
+			ctx.JSON(http.StatusBadRequest, &PageResponse{Error: "Invalid JSON"})
+		}
 		return
 	}
+	req.CreatorIP = ctx.ClientIP()
 
 	response, err := c.service.CreatePage(ctx.Request.Context(), &req)
 	if err != nil {
-		ctx.String(http.StatusInternalServerError, "Internal server error")
+		ctx.JSON(http.StatusInternalServerError, &PageResponse{Error: "Internal server error"})
 		return
 	}
 
 	if response.Error != "" {
-		ctx.String(http.StatusBadRequest, response.Error)
+		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
+	metrics.PagesCreated.WithLabelValues(metrics.SourceAPI).Inc()
 
-	// Return success component for htmx
-	fullURL := ctx.Request.Host + response.URL
-	ctx.Header("Content-Type", "text/html")
-	components.Success(fullURL).Render(ctx.Request.Context(), ctx.Writer)
+	response.URL = links.Absolute(ctx.Request.Context(), response.URL)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Bulk handles bulk operations on several pages
+func (c *Controller) Bulk(ctx *gin.Context) {
+	var req PageBulk
+	if err := ctx.ShouldBind(&req); err != nil {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeBadRequest, "Invalid request data")
+		return
+	}
+
+	response, err := c.service.BulkUpdate(ctx.Request.Context(), &req)
+	if err != nil {
+		api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Internal server error")
+		return
+	}
+
+	if response.Error != "" {
+		api.AbortWithError(ctx, http.StatusBadRequest, api.CodeValidation, response.Error)
+		return
+	}
+
+	// Return result component for htmx or per-item results for API clients
+	if ctx.GetHeader("HX-Request") == "true" {
+		result := &components.BulkResultData{
+			Action:    string(response.Action),
+			Succeeded: response.Succeeded,
+			Failed:    response.Failed,
+		}
+		for _, item := range response.Results {
+			if !item.Success {
+				result.Errors = append(result.Errors, "Page "+strconv.FormatUint(uint64(item.ID), 10)+": "+item.Error)
+			} else if response.Action == BulkActionDelete {
+				result.RestoreIDs = append(result.RestoreIDs, item.ID)
+			}
+		}
+
+		ctx.Header("Content-Type", "text/html")
+		components.BulkResult(result).Render(ctx.Request.Context(), ctx.Writer)
+	} else {
+		ctx.JSON(http.StatusOK, response)
+	}
 }
 
 // GetSharedContent handles requests to view shared content
@@ -222,15 +299,98 @@ func (c *Controller) GetSharedContent(ctx *gin.Context) {
 
 	page, err := c.service.GetPageBySlug(ctx.Request.Context(), slug)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		switch err {
+		case gorm.ErrRecordNotFound:
 			c.serve404(ctx)
-		} else {
+		case ErrTakenDown:
+			c.serveUnavailable(ctx)
+		case ErrQuarantined:
+			c.serveUnderReview(ctx)
+		default:
 			ctx.String(http.StatusInternalServerError, "Internal server error")
 		}
 		return
 	}
 
-	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page.HTMLContent))
+	if page.PasswordProtected {
+		c.servePasswordPrompt(ctx, slug, "")
+		return
+	}
+
+	// Live pages reload open viewers through an event stream on the same URL
+	if page.Live && wantsEventStream(ctx) {
+		c.streamUpdates(ctx, slug)
+		return
+	}
+
+	content := page.HTMLContent
+	if page.Live {
+		ctx.Header("Cache-Control", "no-cache")
+		content = injectLiveReload(content)
+	}
+	if page.ReportBanner {
+		content = injectReportBanner(ctx, content, slug)
+	}
+
+	c.recordView(ctx, page.ID)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(content))
+}
+
+// UnlockSharedContent handles password submissions for protected shared content
+func (c *Controller) UnlockSharedContent(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	page, err := c.service.UnlockPage(ctx.Request.Context(), slug, ctx.PostForm("password"))
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.serve404(ctx)
+		case ErrTakenDown:
+			c.serveUnavailable(ctx)
+		case ErrQuarantined:
+			c.serveUnderReview(ctx)
+		case ErrInvalidPassword:
+			c.servePasswordPrompt(ctx, slug, "Incorrect password")
+		default:
+			ctx.String(http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	content := page.HTMLContent
+	if page.ReportBanner {
+		content = injectReportBanner(ctx, content, slug)
+	}
+
+	c.recordView(ctx, page.ID)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(content))
+}
+
+// recordView counts a view of a page; failing to count it does not fail the request
+func (c *Controller) recordView(ctx *gin.Context, id uint) {
+	metrics.PageViews.Inc()
+	if err := c.service.RecordView(ctx.Request.Context(), id); err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("recording page view failed", "error", err)
+	}
+}
+
+// formError reports a failed form submission, as an alert for htmx or as text otherwise
+func (c *Controller) formError(ctx *gin.Context, status int, message string) {
+	if ctx.GetHeader("HX-Request") != "true" {
+		ctx.String(status, message)
+		return
+	}
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	components.Error(message).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// servePasswordPrompt renders the password form for protected content
+func (c *Controller) servePasswordPrompt(ctx *gin.Context, slug, errorMessage string) {
+	ctx.Status(http.StatusUnauthorized)
+	ctx.Header("Content-Type", "text/html")
+	pages.PasswordPrompt(slug, errorMessage).Render(ctx.Request.Context(), ctx.Writer)
 }
 
 // serve404 renders a 404 error page
@@ -0,0 +1,245 @@
+package page
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/views/pages"
+)
+
+// dashboardNotices confirms changes made from the dashboard, keyed by the done query parameter
+var dashboardNotices = map[string]string{
+	"updated":     "Page updated.",
+	"deleted":     "Page deleted.",
+	"quarantined": "Page updated. It is hidden until an administrator reviews it, because the content scanner found it suspicious.",
+}
+
+// MyPages handles the dashboard listing the signed-in user's pages
+func (c *Controller) MyPages(ctx *gin.Context) {
+	ownerID, ok := signedInOwner(ctx)
+	if !ok {
+		return
+	}
+
+	var filter PageFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid filter parameters")
+		return
+	}
+	filter.OwnerID = &ownerID
+
+	page, pageSize := api.ParsePagination(ctx)
+	pagesList, total, err := c.service.SearchPages(ctx.Request.Context(), &filter, page, pageSize)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing own pages failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading pages")
+		return
+	}
+
+	categories, ok := c.categoryChoices(ctx)
+	if !ok {
+		return
+	}
+
+	data := &pages.MyPagesData{
+		Pages:      make([]*pages.PageData, len(pagesList)),
+		Categories: categories,
+		Query:      filter.Query,
+		CategoryID: filter.CategoryID,
+		Page:       page,
+		TotalPages: api.NewPagination(page, pageSize, total).TotalPages,
+		Total:      total,
+		Notice:     dashboardNotices[ctx.Query("done")],
+	}
+	for i, p := range pagesList {
+		data.Pages[i] = &pages.PageData{
+			ID:           p.ID,
+			Slug:         p.Slug,
+			Title:        p.Title,
+			CategoryID:   p.CategoryID,
+			CategoryName: p.CategoryName,
+			Tags:         p.Tags,
+			CreatedAt:    p.CreatedAt,
+		}
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	pages.MyPages(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// MyPageEdit handles the form for editing one of the signed-in user's pages
+func (c *Controller) MyPageEdit(ctx *gin.Context) {
+	if _, ok := signedInOwner(ctx); !ok {
+		return
+	}
+
+	detail, err := c.service.GetOwnedPage(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.serve404(ctx)
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("loading own page failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading page")
+		return
+	}
+
+	categories, ok := c.categoryChoices(ctx)
+	if !ok {
+		return
+	}
+
+	c.renderMyPageEdit(ctx, http.StatusOK, &pages.MyPageEditData{
+		Slug:        detail.Slug,
+		Title:       detail.Title,
+		HTMLContent: detail.HTMLContent,
+		CategoryID:  detail.CategoryID,
+		Categories:  categories,
+	})
+}
+
+// MyPageUpdate handles changes to one of the signed-in user's pages, either from
+// the edit form or from re-categorising it in the dashboard. Only fields present
+// in the form are changed.
+func (c *Controller) MyPageUpdate(ctx *gin.Context) {
+	if _, ok := signedInOwner(ctx); !ok {
+		return
+	}
+
+	if err := ctx.Request.ParseForm(); err != nil {
+		if api.BodyTooLarge(err) {
+			ctx.String(http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
+		ctx.String(http.StatusBadRequest, "Invalid form")
+		return
+	}
+
+	var req PageUpdate
+	if title, ok := ctx.GetPostForm("title"); ok {
+		req.Title = &title
+	}
+	if content, ok := ctx.GetPostForm("html_content"); ok {
+		req.HTMLContent = &content
+	}
+	// An empty category removes the page from its category
+	if categoryIDStr, ok := ctx.GetPostForm("category_id"); ok {
+		var id uint
+		if categoryIDStr != "" {
+			categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
+			if err != nil {
+				ctx.String(http.StatusBadRequest, "Invalid category ID")
+				return
+			}
+			id = uint(categoryID)
+		}
+		req.CategoryID = &id
+	}
+
+	// No edit token is passed, so the update is authorised by ownership alone
+	slug := ctx.Param("slug")
+	response, err := c.service.UpdatePage(ctx.Request.Context(), slug, "", &req)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound || err == ErrInvalidEditToken {
+			c.serve404(ctx)
+			return
+		}
+		ctx.String(http.StatusInternalServerError, "Error updating page")
+		return
+	}
+
+	if response.Error != "" {
+		categories, ok := c.categoryChoices(ctx)
+		if !ok {
+			return
+		}
+		data := &pages.MyPageEditData{
+			Slug:       slug,
+			CategoryID: req.CategoryID,
+			Categories: categories,
+			Error:      response.Error,
+		}
+		if req.CategoryID != nil && *req.CategoryID == 0 {
+			data.CategoryID = nil
+		}
+		if req.Title != nil {
+			data.Title = *req.Title
+		}
+		if content, ok := ctx.GetPostForm("html_content"); ok {
+			data.HTMLContent = content
+		} else if detail, err := c.service.GetOwnedPage(ctx.Request.Context(), slug); err == nil {
+			data.HTMLContent = detail.HTMLContent
+			data.Title = detail.Title
+		}
+		c.renderMyPageEdit(ctx, http.StatusUnprocessableEntity, data)
+		return
+	}
+
+	done := "updated"
+	if response.Quarantined {
+		done = "quarantined"
+	}
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/me/pages?done="+done))
+}
+
+// MyPageDelete handles deleting one of the signed-in user's pages
+func (c *Controller) MyPageDelete(ctx *gin.Context) {
+	if _, ok := signedInOwner(ctx); !ok {
+		return
+	}
+
+	// No edit token is passed, so the deletion is authorised by ownership alone
+	if err := c.service.DeletePage(ctx.Request.Context(), ctx.Param("slug"), ""); err != nil {
+		if err == gorm.ErrRecordNotFound || err == ErrInvalidEditToken {
+			c.serve404(ctx)
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("deleting own page failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error deleting page")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/me/pages?done=deleted"))
+}
+
+// categoryChoices loads the categories a page can be moved to, writing an error response when that fails
+func (c *Controller) categoryChoices(ctx *gin.Context) ([]pages.CategoryChoice, bool) {
+	options, err := c.service.GetCategoryOptions(ctx.Request.Context())
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing categories failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading categories")
+		return nil, false
+	}
+
+	choices := make([]pages.CategoryChoice, len(options))
+	for i, option := range options {
+		choices[i] = pages.CategoryChoice{ID: option.ID, Name: option.Name}
+	}
+	return choices, true
+}
+
+// renderMyPageEdit renders the page edit form
+func (c *Controller) renderMyPageEdit(ctx *gin.Context, status int, data *pages.MyPageEditData) {
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.MyPageEdit(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// signedInOwner returns the signed-in user's ID, or redirects to the sign-in
+// page and returns false, coming back to the dashboard afterwards
+func signedInOwner(ctx *gin.Context) (uint, bool) {
+	identity := auth.FromContext(ctx.Request.Context())
+	if identity == nil {
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/signin?next="+url.QueryEscape("/me/pages")))
+		return 0, false
+	}
+	return identity.UserID, true
+}
@@ -0,0 +1,70 @@
+package page
+
+import "sync"
+
+// broadcaster notifies subscribers when the page behind a slug changes
+type broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+	closed      bool
+}
+
+// newBroadcaster creates an empty broadcaster
+func newBroadcaster() *broadcaster {
+	return &broadcaster{subscribers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// subscribe registers for change notifications on a slug and returns a function that unregisters
+func (b *broadcaster) subscribe(slug string) (<-chan struct{}, func()) {
+	// Buffered so that a change arriving while the subscriber is busy is not lost
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subscribers[slug] == nil {
+		b.subscribers[slug] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[slug][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers[slug], ch)
+		if len(b.subscribers[slug]) == 0 {
+			delete(b.subscribers, slug)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// close closes every subscriber channel and refuses new subscriptions
+func (b *broadcaster) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, channels := range b.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+	}
+	b.subscribers = make(map[string]map[chan struct{}]struct{})
+	b.closed = true
+}
+
+// publish notifies every subscriber of a slug without blocking
+func (b *broadcaster) publish(slug string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[slug] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A notification is already pending
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package page
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines the interface for page data access operations
 type Repository interface {
@@ -16,6 +19,15 @@ type Repository interface {
 	// List retrieves a paginated list of pages
 	List(ctx context.Context, offset, limit int) ([]*PageList, error)
 
+	// Search retrieves a paginated list of pages matching a filter
+	Search(ctx context.Context, filter *PageFilter, offset, limit int) ([]*PageList, error)
+
+	// CountSearch returns the total number of pages matching a filter
+	CountSearch(ctx context.Context, filter *PageFilter) (int64, error)
+
+	// GetMetadataBySlug retrieves page information without its content by slug
+	GetMetadataBySlug(ctx context.Context, slug string) (*PageMetadata, error)
+
 	// ListByCategory retrieves a paginated list of pages filtered by category
 	ListByCategory(ctx context.Context, categoryID uint, offset, limit int) ([]*PageList, error)
 
@@ -33,6 +45,97 @@ type Repository interface {
 
 	// Exists checks if a slug already exists
 	Exists(ctx context.Context, slug string) (bool, error)
+
+	// GetByIDWithDeleted retrieves a page by its ID, including soft-deleted pages
+	GetByIDWithDeleted(ctx context.Context, id uint) (*Page, error)
+
+	// Restore restores a soft-deleted page by ID
+	Restore(ctx context.Context, id uint) error
+
+	// HardDelete permanently deletes a page by ID, along with its tags
+	HardDelete(ctx context.Context, id uint) error
+
+	// IncrementViews adds one to the view count of a page
+	IncrementViews(ctx context.Context, id uint) error
+
+	// AdminList retrieves a paginated list of pages with their owners, including soft-deleted pages
+	AdminList(ctx context.Context, filter *AdminPageFilter, offset, limit int) ([]*AdminPageList, error)
+
+	// AdminCount returns the number of pages matching a moderation filter
+	AdminCount(ctx context.Context, filter *AdminPageFilter) (int64, error)
+
+	// TakeDown marks a page as taken down for a reason, so that it is no longer served
+	TakeDown(ctx context.Context, id uint, cause ReportReason) error
+
+	// Reinstate clears the takedown and quarantine of a page
+	Reinstate(ctx context.Context, id uint) error
+
+	// SetScan records the content scanner's score for a page, quarantining it when asked
+	SetScan(ctx context.Context, id uint, score int, quarantine bool) error
+
+	// Release clears the quarantine of a page
+	Release(ctx context.Context, id uint) error
+
+	// CreateReport stores a report about a page
+	CreateReport(ctx context.Context, report *PageReport) error
+
+	// HasOpenReport checks if a page already has a report waiting for review from an address
+	HasOpenReport(ctx context.Context, pageID uint, reporterIP string) (bool, error)
+
+	// HasOpenScanReport checks if a page already has a report from the content scanner waiting for review
+	HasOpenScanReport(ctx context.Context, pageID uint) (bool, error)
+
+	// GetReport retrieves a report by its ID
+	GetReport(ctx context.Context, id uint) (*PageReport, error)
+
+	// ListReports retrieves a paginated list of reports with the pages they are about
+	ListReports(ctx context.Context, filter *ReportFilter, offset, limit int) ([]*ReportList, error)
+
+	// CountReports returns the number of reports matching a filter
+	CountReports(ctx context.Context, filter *ReportFilter) (int64, error)
+
+	// ResolveReport sets the status of an open report, recording who resolved it
+	ResolveReport(ctx context.Context, id uint, status ReportStatus, resolverID *uint) error
+
+	// ResolvePageReports sets the status of every open report about a page, recording who resolved them
+	ResolvePageReports(ctx context.Context, pageID uint, status ReportStatus, resolverID *uint) error
+
+	// DismissScanReports dismisses every open report about a page filed by the content scanner
+	DismissScanReports(ctx context.Context, pageID uint) error
+
+	// AddTags attaches tags to a page, ignoring tags it already has
+	AddTags(ctx context.Context, id uint, tags []string) error
+
+	// GetTags retrieves the tags of several pages keyed by page ID
+	GetTags(ctx context.Context, ids []uint) (map[uint][]string, error)
+
+	// CategoryExists checks if a category exists
+	CategoryExists(ctx context.Context, categoryID uint) (bool, error)
+
+	// GetCategoryIDByName retrieves the ID of a category by its name
+	GetCategoryIDByName(ctx context.Context, name string) (uint, error)
+
+	// GetUserIDByName retrieves the ID of a user by their username
+	GetUserIDByName(ctx context.Context, username string) (uint, error)
+
+	// CategoryOptions retrieves every category, ordered by name
+	CategoryOptions(ctx context.Context) ([]*CategoryOption, error)
+
+	// ListForExport retrieves every page that has not been deleted, quarantined or
+	// taken down, including its content
+	ListForExport(ctx context.Context) ([]*PageExport, error)
+
+	// Purge permanently deletes pages soft-deleted before a time, along with their tags
+	Purge(ctx context.Context, before time.Time) (int64, error)
+
+	// DeleteExpired soft deletes pages whose expiry has passed
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+
+	// Stats returns aggregate statistics about stored pages
+	Stats(ctx context.Context) (*PageStats, error)
+
+	// WithTransaction runs fn with a repository bound to a single database transaction
+	WithTransaction(ctx context.Context, fn func(repo Repository) error) error
 }
 
 // Service defines the interface for page business logic operations
@@ -43,12 +146,93 @@ type Service interface {
 	// GetPageBySlug retrieves a page by its slug for viewing
 	GetPageBySlug(ctx context.Context, slug string) (*PageDetail, error)
 
+	// UnlockPage retrieves a password-protected page by its slug after checking the password
+	UnlockPage(ctx context.Context, slug, password string) (*PageDetail, error)
+
+	// GetPageMetadata retrieves page information without its content by slug
+	GetPageMetadata(ctx context.Context, slug string) (*PageMetadata, error)
+
+	// SearchPages retrieves a paginated list of pages matching a filter
+	SearchPages(ctx context.Context, filter *PageFilter, page, pageSize int) ([]*PageList, int64, error)
+
+	// UpdatePage updates a page identified by slug if the request is made by its owner or an administrator, or carries its edit token
+	// Expired pages cannot be updated and are reported as not found
+	UpdatePage(ctx context.Context, slug, editToken string, req *PageUpdate) (*PageMetadataResponse, error)
+
+	// DeletePage soft deletes a page identified by slug if the request is made by its owner or an administrator, or carries its edit token
+	DeletePage(ctx context.Context, slug, editToken string) error
+
+	// GetOwnedPage retrieves a page by slug if the request is made by its owner, or gorm.ErrRecordNotFound otherwise
+	GetOwnedPage(ctx context.Context, slug string) (*PageDetail, error)
+
+	// GetCategoryOptions retrieves every category a page can be moved to
+	GetCategoryOptions(ctx context.Context) ([]*CategoryOption, error)
+
+	// RecordView counts a view of a page
+	RecordView(ctx context.Context, id uint) error
+
+	// AdminListPages retrieves a paginated list of pages for moderation, including soft-deleted pages
+	AdminListPages(ctx context.Context, filter *AdminPageFilter, page, pageSize int) ([]*AdminPageList, int64, error)
+
+	// HardDeletePage permanently deletes a page by ID, including a soft-deleted one
+	HardDeletePage(ctx context.Context, id uint) error
+
+	// RestorePage restores a soft-deleted page by ID, returning ErrNotDeleted when it is not deleted
+	RestorePage(ctx context.Context, id uint) error
+
+	// ReportPage records a visitor's report about a page for moderation
+	ReportPage(ctx context.Context, slug string, req *ReportCreate) (*ReportResponse, error)
+
+	// ListReports retrieves a paginated list of reports for the moderation queue
+	ListReports(ctx context.Context, filter *ReportFilter, page, pageSize int) ([]*ReportList, int64, error)
+
+	// DismissReport closes a report without acting on it, returning ErrReportResolved when it is not open.
+	// Dismissing the content scanner's report about a quarantined page releases the page.
+	DismissReport(ctx context.Context, id uint) error
+
+	// TakeDownReportedPage takes down the page a report is about and closes every open report about it
+	TakeDownReportedPage(ctx context.Context, reportID uint) error
+
+	// ReinstatePage serves a taken-down or quarantined page again, returning ErrNotTakenDown when it is neither
+	ReinstatePage(ctx context.Context, id uint) error
+
+	// Subscribe registers for change notifications on a page and returns a function that unregisters
+	Subscribe(slug string) (<-chan struct{}, func())
+
+	// CloseSubscriptions closes every change subscription so that open event streams end
+	CloseSubscriptions()
+
+	// ResolveCategory resolves a category given by ID or by name
+	ResolveCategory(ctx context.Context, ref string) (*uint, error)
+
 	// GetPagesList retrieves a paginated list of pages
 	GetPagesList(ctx context.Context, page, pageSize int) ([]*PageList, int64, error)
 
 	// GetPagesByCategory retrieves a paginated list of pages filtered by category
 	GetPagesByCategory(ctx context.Context, categoryID uint, page, pageSize int) ([]*PageList, int64, error)
 
+	// BulkUpdate applies an operation to several pages in a single transaction.
+	// Each page must belong to the requesting user unless they are an administrator.
+	BulkUpdate(ctx context.Context, req *PageBulk) (*PageBulkResponse, error)
+
+	// ExportPages retrieves every page that has not been deleted, quarantined or
+	// taken down, with its content and tags
+	ExportPages(ctx context.Context) ([]*PageExport, error)
+
+	// ImportPage creates a page from an export, keeping its slug when it is still free.
+	// The content is validated and scanned like newly shared content, and the page
+	// is given back to its owner when a user with the exported username exists.
+	ImportPage(ctx context.Context, export *PageExport, categoryID *uint) (*PageResponse, error)
+
+	// PurgeDeletedPages permanently deletes pages soft-deleted before a time
+	PurgeDeletedPages(ctx context.Context, before time.Time) (int64, error)
+
+	// DeleteExpiredPages moves pages whose expiry has passed to the trash
+	DeleteExpiredPages(ctx context.Context) (int64, error)
+
+	// GetStats returns aggregate statistics about stored pages
+	GetStats(ctx context.Context) (*PageStats, error)
+
 	// GenerateUniqueSlug generates a unique slug for a new page
 	GenerateUniqueSlug(ctx context.Context) (string, error)
 
@@ -0,0 +1,69 @@
+package page
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveReloadScript reloads a live page when the server reports a change on its event stream
+const liveReloadScript = `<script>(function(){var s=new EventSource(location.pathname);s.addEventListener("update",function(){s.close();location.reload();});})();</script>`
+
+// heartbeatInterval is how often an idle event stream sends a comment to keep proxies from closing it
+const heartbeatInterval = 30 * time.Second
+
+// wantsEventStream reports whether a request asks for Server-Sent Events instead of the page itself
+func wantsEventStream(ctx *gin.Context) bool {
+	return strings.Contains(ctx.GetHeader("Accept"), "text/event-stream")
+}
+
+// streamUpdates sends an "update" event to the client whenever the page changes
+func (c *Controller) streamUpdates(ctx *gin.Context, slug string) {
+	updates, unsubscribe := c.service.Subscribe(slug)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// The stream outlives the server's write timeout, so lift the deadline for this response
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case _, ok := <-updates:
+			if !ok {
+				// The server is shutting down
+				return false
+			}
+			ctx.SSEvent("update", slug)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// injectLiveReload adds the live reload script to the end of the page body
+func injectLiveReload(html string) string {
+	return injectBeforeBodyEnd(html, liveReloadScript)
+}
+
+// injectBeforeBodyEnd inserts markup just before the closing body tag, or appends it when there is none
+func injectBeforeBodyEnd(html, markup string) string {
+	if i := strings.LastIndex(strings.ToLower(html), "</body>"); i >= 0 {
+		return html[:i] + markup + html[i:]
+	}
+	return html + markup
+}
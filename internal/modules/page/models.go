@@ -7,27 +7,178 @@ import (
 
 // Page represents the database table for shared HTML pages
 type Page struct {
-	ID          uint           `gorm:"primarykey" json:"id"`
-	Slug        string         `gorm:"uniqueIndex;not null" json:"slug"`
-	HTMLContent string         `gorm:"type:text;not null" json:"html_content"`
-	Title       string         `gorm:"size:255" json:"title,omitempty"`
-	CategoryID  *uint          `gorm:"index" json:"category_id,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primarykey" json:"id"`
+	Slug          string         `gorm:"uniqueIndex;not null" json:"slug"`
+	HTMLContent   string         `gorm:"type:text;not null" json:"html_content"`
+	Title         string         `gorm:"size:255" json:"title,omitempty"`
+	CategoryID    *uint          `gorm:"index" json:"category_id,omitempty"`
+	EditTokenHash string         `gorm:"size:64" json:"-"`
+	PasswordHash  string         `gorm:"size:60" json:"-"`
+	ExpiresAt     *time.Time     `gorm:"index" json:"expires_at,omitempty"`
+	Live          bool           `gorm:"not null;default:false" json:"live"`
+	OwnerID       *uint          `gorm:"index" json:"owner_id,omitempty"`
+	CreatorIP     string         `gorm:"size:45" json:"-"`
+	Views         int64          `gorm:"not null;default:0" json:"views"`
+	TakenDownAt   *time.Time     `gorm:"index" json:"-"`
+	TakedownCause string         `gorm:"size:32" json:"-"`
+	ScanScore     int            `gorm:"not null;default:0" json:"-"`
+	QuarantinedAt *time.Time     `gorm:"index" json:"-"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // PageCreate represents the data needed to create a new page
 type PageCreate struct {
-	HTMLContent string `json:"html_content" binding:"required"`
-	Title       string `json:"title,omitempty"`
-	CategoryID  *uint  `json:"category_id,omitempty"`
+	HTMLContent string     `json:"html_content" binding:"required"`
+	Title       string     `json:"title,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Password    string     `json:"password,omitempty"`
+	Live        bool       `json:"live,omitempty"`
+	// CreatorIP is the address the page was created from, recorded for moderation
+	CreatorIP string `json:"-"`
 }
 
 // PageUpdate represents the data that can be updated for a page
 type PageUpdate struct {
 	HTMLContent *string `json:"html_content,omitempty"`
 	Title       *string `json:"title,omitempty"`
+	// CategoryID moves the page to a category, or out of its category when 0
+	CategoryID *uint `json:"category_id,omitempty"`
+	// Live turns reloading of open viewers on or off
+	Live *bool `json:"live,omitempty"`
+}
+
+// PageTag represents a tag attached to a shared page
+type PageTag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	PageID    uint      `gorm:"uniqueIndex:idx_page_tag;not null" json:"page_id"`
+	Name      string    `gorm:"uniqueIndex:idx_page_tag;size:64;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportReason identifies why a page was reported
+type ReportReason string
+
+const (
+	// ReportPhishing is a page that imitates another site to collect credentials or payment details
+	ReportPhishing ReportReason = "phishing"
+	// ReportMalware is a page that serves or links to malicious software
+	ReportMalware ReportReason = "malware"
+	// ReportSpam is unsolicited advertising or search spam
+	ReportSpam ReportReason = "spam"
+	// ReportIllegal is content that is unlawful for another reason
+	ReportIllegal ReportReason = "illegal"
+	// ReportCopyright is content shared without the rights holder's permission
+	ReportCopyright ReportReason = "copyright"
+	// ReportOther is anything else, explained in the report details
+	ReportOther ReportReason = "other"
+)
+
+// ReportReasons lists the reasons a page can be reported for, in the order they are offered
+var ReportReasons = []ReportReason{ReportPhishing, ReportMalware, ReportSpam, ReportIllegal, ReportCopyright, ReportOther}
+
+// Label returns the reason as offered to people reporting a page
+func (r ReportReason) Label() string {
+	switch r {
+	case ReportPhishing:
+		return "Phishing or fraud"
+	case ReportMalware:
+		return "Malware"
+	case ReportSpam:
+		return "Spam"
+	case ReportIllegal:
+		return "Illegal content"
+	case ReportCopyright:
+		return "Copyright infringement"
+	case ReportOther:
+		return "Something else"
+	}
+	return string(r)
+}
+
+// ReportStatus is the moderation state of a report
+type ReportStatus string
+
+const (
+	// ReportOpen is a report waiting for review
+	ReportOpen ReportStatus = "open"
+	// ReportDismissed is a report reviewed without action
+	ReportDismissed ReportStatus = "dismissed"
+	// ReportActioned is a report that led to its page being taken down
+	ReportActioned ReportStatus = "actioned"
+)
+
+// ReportSource identifies who filed a report
+type ReportSource string
+
+const (
+	// ReportFromVisitor is a report sent with the report form
+	ReportFromVisitor ReportSource = "visitor"
+	// ReportFromScanner is a report filed by the content scanner when a page is flagged or quarantined
+	ReportFromScanner ReportSource = "scanner"
+)
+
+// PageReport represents a report about a shared page, kept for moderation
+type PageReport struct {
+	ID            uint         `gorm:"primarykey"`
+	PageID        uint         `gorm:"index;not null"`
+	Source        ReportSource `gorm:"size:16;not null;default:visitor"`
+	Reason        ReportReason `gorm:"size:32;not null"`
+	Details       string       `gorm:"size:2000"`
+	ReporterEmail string       `gorm:"size:255"`
+	ReporterID    *uint
+	ReporterIP    string       `gorm:"size:45"`
+	UserAgent     string       `gorm:"size:255"`
+	Status        ReportStatus `gorm:"size:16;not null;default:open;index"`
+	ResolvedByID  *uint
+	ResolvedAt    *time.Time
+	CreatedAt     time.Time
+}
+
+// ReportCreate represents the data submitted with the report form
+type ReportCreate struct {
+	Reason  ReportReason `form:"reason"`
+	Details string       `form:"details"`
+	Email   string       `form:"email"`
+	// ReporterIP and UserAgent describe where the report came from; they are set by the controller
+	ReporterIP string `form:"-"`
+	UserAgent  string `form:"-"`
+}
+
+// ReportResponse represents the outcome of submitting a report
+type ReportResponse struct {
+	Error string
+}
+
+// ReportFilter represents the filters applied when listing reports
+type ReportFilter struct {
+	// Status is "open" for reports waiting for review, "resolved" for the rest, or empty for all
+	Status string `form:"status"`
+}
+
+// ReportList represents a report as listed in the moderation queue, with the page it is about
+type ReportList struct {
+	ID            uint
+	PageID        uint
+	Slug          string
+	Title         string
+	Source        ReportSource
+	Reason        ReportReason
+	Details       string
+	ReporterEmail string
+	ReporterName  *string
+	ReporterIP    string
+	UserAgent     string
+	Status        ReportStatus
+	ResolverName  *string
+	ResolvedAt    *time.Time
+	CreatedAt     time.Time
+	// PageTakenDownAt, PageQuarantinedAt and PageDeletedAt describe the reported page as it is now
+	PageTakenDownAt   *time.Time
+	PageQuarantinedAt *time.Time
+	PageDeletedAt     *time.Time
 }
 
 // PageList represents a simplified page for listing purposes
@@ -37,28 +188,189 @@ type PageList struct {
 	Title        string    `json:"title"`
 	CategoryID   *uint     `json:"category_id,omitempty"`
 	CategoryName *string   `json:"category_name,omitempty"`
+	Tags         []string  `gorm:"-" json:"tags,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
 // PageDetail represents detailed page information
 type PageDetail struct {
-	ID           uint      `json:"id"`
-	Slug         string    `json:"slug"`
-	HTMLContent  string    `json:"html_content"`
-	Title        string    `json:"title"`
-	CategoryID   *uint     `json:"category_id,omitempty"`
-	CategoryName *string   `json:"category_name,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                uint       `json:"id"`
+	Slug              string     `json:"slug"`
+	HTMLContent       string     `json:"html_content"`
+	Title             string     `json:"title"`
+	CategoryID        *uint      `json:"category_id,omitempty"`
+	CategoryName      *string    `json:"category_name,omitempty"`
+	PasswordProtected bool       `json:"password_protected"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	Live              bool       `json:"live"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	// ReportBanner adds a link to the report form when the page is viewed
+	ReportBanner bool `json:"-"`
+}
+
+// PageMetadata represents page information without its HTML content
+type PageMetadata struct {
+	ID                uint       `json:"id"`
+	Slug              string     `json:"slug"`
+	Title             string     `json:"title"`
+	CategoryID        *uint      `json:"category_id,omitempty"`
+	CategoryName      *string    `json:"category_name,omitempty"`
+	Tags              []string   `gorm:"-" json:"tags,omitempty"`
+	Size              int64      `json:"size"`
+	PasswordProtected bool       `json:"password_protected"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	Live              bool       `json:"live"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// PageFilter represents the filters applied when searching pages
+type PageFilter struct {
+	CategoryID *uint  `form:"category_id"`
+	Tag        string `form:"tag"`
+	Query      string `form:"q"`
+	// OwnerID restricts results to one user's pages; it is never read from the request
+	OwnerID *uint `form:"-"`
+}
+
+// AdminPageFilter represents the filters applied when listing pages for moderation
+type AdminPageFilter struct {
+	Query string `form:"q"`
+	// Status is "deleted" for soft-deleted pages, "taken_down" for pages taken down after a report,
+	// "quarantined" for pages the content scanner holds for review, "active" for the rest, or empty for all
+	Status string `form:"status"`
+}
+
+// AdminPageList represents a page as listed for moderation, including soft-deleted pages
+type AdminPageList struct {
+	ID        uint
+	Slug      string
+	Title     string
+	OwnerID   *uint
+	OwnerName *string
+	Size      int64
+	Views     int64
+	CreatorIP string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	// TakenDownAt is set when the page was taken down after a report, for the reason in TakedownCause
+	TakenDownAt   *time.Time
+	TakedownCause ReportReason
+	// ScanScore is the content scanner's score, and QuarantinedAt is set while the scanner holds the page for review
+	ScanScore     int
+	QuarantinedAt *time.Time
+	// OpenReports is the number of reports about the page waiting for review
+	OpenReports int64
+}
+
+// CategoryOption represents a category offered when choosing where a page belongs
+type CategoryOption struct {
+	ID   uint
+	Name string
 }
 
 // PageResponse represents the API response for page operations
 type PageResponse struct {
-	URL   string `json:"url,omitempty"`
-	Error string `json:"error,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Slug      string `json:"slug,omitempty"`
+	EditToken string `json:"edit_token,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Code identifies why content was rejected, such as too_large or binary_content
+	Code string `json:"code,omitempty"`
+	// Quarantined reports that the content scanner holds the page for review before it is shown
+	Quarantined bool `json:"quarantined,omitempty"`
+}
+
+// BulkAction identifies an operation applied to several pages at once
+type BulkAction string
+
+const (
+	// BulkActionMove moves pages to another category
+	BulkActionMove BulkAction = "move"
+	// BulkActionTag adds tags to pages
+	BulkActionTag BulkAction = "tag"
+	// BulkActionDelete soft deletes pages
+	BulkActionDelete BulkAction = "delete"
+	// BulkActionRestore restores soft-deleted pages
+	BulkActionRestore BulkAction = "restore"
+)
+
+// PageBulk represents an operation applied to several pages at once
+type PageBulk struct {
+	Action     BulkAction `json:"action" form:"action" binding:"required"`
+	PageIDs    []uint     `json:"page_ids" form:"page_ids" binding:"required"`
+	CategoryID *uint      `json:"category_id,omitempty" form:"category_id"`
+	Tags       []string   `json:"tags,omitempty" form:"tags"`
+}
+
+// PageBulkResult represents the outcome of a bulk operation for a single page
+type PageBulkResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PageBulkResponse represents the API response for bulk page operations
+type PageBulkResponse struct {
+	Action    BulkAction        `json:"action"`
+	Results   []*PageBulkResult `json:"results,omitempty"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// PageMetadataResponse represents the API response for page updates
+type PageMetadataResponse struct {
+	Page  *PageMetadata `json:"page,omitempty"`
+	Error string        `json:"error,omitempty"`
+	// Code identifies why content was rejected, such as too_large or binary_content
+	Code string `json:"code,omitempty"`
+	// Quarantined reports that the content scanner holds the page for review, in which case Page is empty
+	Quarantined bool `json:"quarantined,omitempty"`
+}
+
+// PageExport represents a page in an export archive. It carries the edit token and
+// password hashes so that imported pages stay editable and protected. Users are not
+// exported, so the owner is named by username and restored only if that user exists.
+type PageExport struct {
+	ID            uint       `json:"-"`
+	Slug          string     `json:"slug"`
+	Title         string     `json:"title"`
+	CategoryName  *string    `json:"category_name,omitempty"`
+	OwnerName     *string    `json:"owner_name,omitempty"`
+	Tags          []string   `gorm:"-" json:"tags,omitempty"`
+	HTMLContent   string     `json:"-"`
+	EditTokenHash string     `json:"edit_token_hash,omitempty"`
+	PasswordHash  string     `json:"password_hash,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Live          bool       `json:"live,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// PageStats represents aggregate statistics about stored pages
+type PageStats struct {
+	Active            int64 `json:"active"`
+	Expired           int64 `json:"expired"`
+	Deleted           int64 `json:"deleted"`
+	PasswordProtected int64 `json:"password_protected"`
+	Live              int64 `json:"live"`
+	ContentBytes      int64 `json:"content_bytes"`
+	Tags              int64 `json:"tags"`
 }
 
 // TableName returns the table name for the Page model
 func (Page) TableName() string {
 	return "shared_content"
 }
+
+// TableName returns the table name for the PageReport model
+func (PageReport) TableName() string {
+	return "page_reports"
+}
+
+// TableName returns the table name for the PageTag model
+func (PageTag) TableName() string {
+	return "page_tags"
+}
@@ -0,0 +1,176 @@
+package page
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/metrics"
+)
+
+// Headers accepted by the raw upload endpoints as alternatives to query parameters
+const (
+	TitleHeader    = "X-Title"
+	CategoryHeader = "X-Category"
+	SlugHeader     = "X-Slug"
+	// ErrorCodeHeader carries the reason content was rejected, such as too_large or binary_content
+	ErrorCodeHeader = "X-Error-Code"
+	// QuarantinedHeader is set to "true" when the page was saved but is held for review
+	QuarantinedHeader = "X-Quarantined"
+)
+
+// CreateRaw handles uploads where the request body is the HTML content itself
+func (c *Controller) CreateRaw(ctx *gin.Context) {
+	content, ok := c.readRawContent(ctx)
+	if !ok {
+		return
+	}
+
+	categoryID, ok := c.rawCategory(ctx)
+	if !ok {
+		return
+	}
+
+	req := &PageCreate{
+		HTMLContent: content,
+		Title:       rawParam(ctx, "title", TitleHeader),
+		CategoryID:  categoryID,
+		CreatorIP:   ctx.ClientIP(),
+	}
+	response, err := c.service.CreatePage(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, "Error creating page\n")
+		return
+	}
+
+	if response.Error != "" {
+		rawContentError(ctx, response.Code, response.Error)
+		return
+	}
+
+	metrics.PagesCreated.WithLabelValues(metrics.SourceAPI).Inc()
+	ctx.Header(SlugHeader, response.Slug)
+	ctx.Header(EditTokenHeader, response.EditToken)
+	if response.Quarantined {
+		rawHeld(ctx, api.AbsoluteURL(ctx, response.URL))
+		return
+	}
+	ctx.String(http.StatusCreated, api.AbsoluteURL(ctx, response.URL)+"\n")
+}
+
+// UpdateRaw handles replacing a page's content with the request body
+func (c *Controller) UpdateRaw(ctx *gin.Context) {
+	editToken := ctx.GetHeader(EditTokenHeader)
+	if editToken == "" && auth.FromContext(ctx.Request.Context()) == nil {
+		ctx.String(http.StatusUnauthorized, "Missing "+EditTokenHeader+" header\n")
+		return
+	}
+
+	content, ok := c.readRawContent(ctx)
+	if !ok {
+		return
+	}
+
+	categoryID, ok := c.rawCategory(ctx)
+	if !ok {
+		return
+	}
+
+	req := &PageUpdate{
+		HTMLContent: &content,
+		CategoryID:  categoryID,
+	}
+	if title := rawParam(ctx, "title", TitleHeader); title != "" {
+		req.Title = &title
+	}
+
+	slug := ctx.Param("slug")
+	response, err := c.service.UpdatePage(ctx.Request.Context(), slug, editToken, req)
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			ctx.String(http.StatusNotFound, "Page not found\n")
+		case ErrInvalidEditToken:
+			ctx.String(http.StatusForbidden, "Invalid edit token\n")
+		default:
+			ctx.String(http.StatusInternalServerError, "Error updating page\n")
+		}
+		return
+	}
+
+	if response.Error != "" {
+		rawContentError(ctx, response.Code, response.Error)
+		return
+	}
+
+	ctx.Header(SlugHeader, slug)
+	if response.Quarantined {
+		rawHeld(ctx, api.AbsoluteURL(ctx, "/shared/"+slug))
+		return
+	}
+	ctx.String(http.StatusOK, api.AbsoluteURL(ctx, "/shared/"+slug)+"\n")
+}
+
+// rawHeld reports a page that was saved but is held for review, with its URL
+// still on the first line so that scripts reading it keep working
+func rawHeld(ctx *gin.Context, url string) {
+	ctx.Header(QuarantinedHeader, "true")
+	ctx.String(http.StatusAccepted, url+"\nHeld for review: the page will be served once a moderator approves it\n")
+}
+
+// readRawContent reads the request body as HTML content, writing an error response when it is unusable
+func (c *Controller) readRawContent(ctx *gin.Context) (string, bool) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		if api.BodyTooLarge(err) {
+			rawContentError(ctx, ContentTooLarge, "Request body is too large")
+		} else {
+			ctx.String(http.StatusBadRequest, "Error reading request body\n")
+		}
+		return "", false
+	}
+
+	if len(body) == 0 {
+		ctx.String(http.StatusBadRequest, "No HTML content provided\n")
+		return "", false
+	}
+
+	return string(body), true
+}
+
+// rawContentError reports rejected content with its reason in a header, using 413 for oversized content
+func rawContentError(ctx *gin.Context, code, message string) {
+	status := http.StatusBadRequest
+	if code == ContentTooLarge {
+		status = http.StatusRequestEntityTooLarge
+	}
+	if code != "" {
+		ctx.Header(ErrorCodeHeader, code)
+	}
+	ctx.String(status, message+"\n")
+}
+
+// rawCategory resolves the category given by query parameter or header, writing an error response when it is unknown
+func (c *Controller) rawCategory(ctx *gin.Context) (*uint, bool) {
+	categoryID, err := c.service.ResolveCategory(ctx.Request.Context(), rawParam(ctx, "category", CategoryHeader))
+	if err != nil {
+		if err == ErrCategoryNotFound {
+			ctx.String(http.StatusBadRequest, "Category not found\n")
+		} else {
+			ctx.String(http.StatusInternalServerError, "Error resolving category\n")
+		}
+		return nil, false
+	}
+	return categoryID, true
+}
+
+// rawParam reads a value from the query string, falling back to a request header
+func rawParam(ctx *gin.Context, query, header string) string {
+	if value := ctx.Query(query); value != "" {
+		return value
+	}
+	return ctx.GetHeader(header)
+}
@@ -0,0 +1,256 @@
+package page
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/views/pages"
+)
+
+// reportBanner is a small link to the report form shown in the corner of shared pages
+const reportBanner = `<a href="%s" rel="nofollow" style="position:fixed;right:8px;bottom:8px;z-index:2147483647;padding:4px 8px;border-radius:4px;background:rgba(0,0,0,.6);color:#fff;font:12px/1.4 system-ui,sans-serif;text-decoration:none">Report this page</a>`
+
+// adminReportNotices confirms moderation actions on reports, keyed by the done query parameter
+var adminReportNotices = map[string]string{
+	"dismissed":  "Report dismissed.",
+	"taken-down": "Page taken down. Its open reports are resolved.",
+}
+
+// ReportForm handles the form for reporting a shared page
+func (c *Controller) ReportForm(ctx *gin.Context) {
+	metadata, err := c.service.GetPageMetadata(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.serve404(ctx)
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("loading page to report failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	c.renderReportForm(ctx, http.StatusOK, &pages.ReportFormData{
+		Slug:  metadata.Slug,
+		Title: metadata.Title,
+	})
+}
+
+// SubmitReport handles a report about a shared page
+func (c *Controller) SubmitReport(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	var req ReportCreate
+	if err := ctx.ShouldBind(&req); err != nil {
+		if api.BodyTooLarge(err) {
+			ctx.String(http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
+		ctx.String(http.StatusBadRequest, "Invalid form")
+		return
+	}
+	req.ReporterIP = ctx.ClientIP()
+	req.UserAgent = ctx.Request.UserAgent()
+
+	response, err := c.service.ReportPage(ctx.Request.Context(), slug, &req)
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.serve404(ctx)
+		case ErrTakenDown:
+			c.serveUnavailable(ctx)
+		case ErrQuarantined:
+			c.serveUnderReview(ctx)
+		default:
+			ctx.String(http.StatusInternalServerError, "Error saving report")
+		}
+		return
+	}
+
+	if response.Error != "" {
+		data := &pages.ReportFormData{
+			Slug:    slug,
+			Reason:  string(req.Reason),
+			Details: req.Details,
+			Email:   req.Email,
+			Error:   response.Error,
+		}
+		if metadata, err := c.service.GetPageMetadata(ctx.Request.Context(), slug); err == nil {
+			data.Title = metadata.Title
+		}
+		c.renderReportForm(ctx, http.StatusUnprocessableEntity, data)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	pages.ReportThanks().Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// AdminReports handles the moderation queue of reports, showing open reports unless another status is chosen
+func (c *Controller) AdminReports(ctx *gin.Context) {
+	var filter ReportFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid filter parameters")
+		return
+	}
+	if _, ok := ctx.GetQuery("status"); !ok {
+		filter.Status = string(ReportOpen)
+	}
+
+	page, pageSize := api.ParsePagination(ctx)
+	reports, total, err := c.service.ListReports(ctx.Request.Context(), &filter, page, pageSize)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing reports failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading reports")
+		return
+	}
+
+	data := &pages.AdminReportsData{
+		Reports:    make([]pages.AdminReportData, len(reports)),
+		Status:     filter.Status,
+		Page:       page,
+		TotalPages: api.NewPagination(page, pageSize, total).TotalPages,
+		Total:      total,
+		Notice:     adminReportNotices[ctx.Query("done")],
+	}
+	for i, r := range reports {
+		data.Reports[i] = pages.AdminReportData{
+			ID:              r.ID,
+			PageID:          r.PageID,
+			Slug:            r.Slug,
+			Title:           r.Title,
+			FromScanner:     r.Source == ReportFromScanner,
+			Reason:          r.Reason.Label(),
+			Details:         r.Details,
+			ReporterEmail:   r.ReporterEmail,
+			ReporterName:    r.ReporterName,
+			ReporterIP:      r.ReporterIP,
+			UserAgent:       r.UserAgent,
+			Status:          string(r.Status),
+			ResolverName:    r.ResolverName,
+			ResolvedAt:      r.ResolvedAt,
+			CreatedAt:       r.CreatedAt,
+			PageTakenDown:   r.PageTakenDownAt != nil,
+			PageQuarantined: r.PageQuarantinedAt != nil,
+			PageDeleted:     r.PageDeletedAt != nil,
+		}
+	}
+
+	ctx.Header("Content-Type", "text/html")
+	pages.AdminReports(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// AdminDismissReport handles closing a report without acting on it
+func (c *Controller) AdminDismissReport(ctx *gin.Context) {
+	id, ok := adminReportID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.DismissReport(ctx.Request.Context(), id); err != nil {
+		c.reportActionError(ctx, "dismissing report failed", err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/reports?done=dismissed"))
+}
+
+// AdminTakeDown handles taking down the page a report is about
+func (c *Controller) AdminTakeDown(ctx *gin.Context) {
+	id, ok := adminReportID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.TakeDownReportedPage(ctx.Request.Context(), id); err != nil {
+		c.reportActionError(ctx, "taking down page failed", err)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/reports?done=taken-down"))
+}
+
+// AdminReinstate handles serving a taken-down or quarantined page again
+func (c *Controller) AdminReinstate(ctx *gin.Context) {
+	id, ok := adminPageID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.ReinstatePage(ctx.Request.Context(), id); err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			ctx.String(http.StatusNotFound, "Page not found")
+		case ErrNotTakenDown:
+			ctx.String(http.StatusConflict, "Page is neither taken down nor quarantined")
+		default:
+			logging.FromContext(ctx.Request.Context()).Error("reinstating page failed", "error", err)
+			ctx.String(http.StatusInternalServerError, "Error reinstating page")
+		}
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/pages?done=reinstated"))
+}
+
+// reportActionError writes the response for a moderation action on a report that failed
+func (c *Controller) reportActionError(ctx *gin.Context, message string, err error) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		ctx.String(http.StatusNotFound, "Report not found")
+	case ErrReportResolved:
+		ctx.String(http.StatusConflict, "Report is already resolved")
+	default:
+		logging.FromContext(ctx.Request.Context()).Error(message, "error", err)
+		ctx.String(http.StatusInternalServerError, "Error updating report")
+	}
+}
+
+// renderReportForm renders the report form with the reasons a page can be reported for
+func (c *Controller) renderReportForm(ctx *gin.Context, status int, data *pages.ReportFormData) {
+	data.Reasons = make([]pages.ReportReasonChoice, len(ReportReasons))
+	for i, reason := range ReportReasons {
+		data.Reasons[i] = pages.ReportReasonChoice{Value: string(reason), Label: reason.Label()}
+	}
+
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.ReportForm(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// serveUnavailable renders the notice shown instead of a page that was taken down
+func (c *Controller) serveUnavailable(ctx *gin.Context) {
+	ctx.Status(http.StatusUnavailableForLegalReasons)
+	ctx.Header("Content-Type", "text/html")
+	pages.Unavailable().Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// serveUnderReview renders the notice shown instead of a page the content scanner holds for review
+func (c *Controller) serveUnderReview(ctx *gin.Context) {
+	ctx.Status(http.StatusForbidden)
+	ctx.Header("Content-Type", "text/html")
+	pages.UnderReview().Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// injectReportBanner adds the link to a page's report form to the end of the page body
+func injectReportBanner(ctx *gin.Context, content, slug string) string {
+	href := html.EscapeString(links.Path(ctx.Request.Context(), "/shared/"+slug+"/report"))
+	return injectBeforeBodyEnd(content, fmt.Sprintf(reportBanner, href))
+}
+
+// adminReportID parses the report ID route parameter, writing a 400 response when it is invalid
+func adminReportID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid report ID")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,7 +2,10 @@ package page
 
 import (
 	"context"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // repository implements the Repository interface using GORM
@@ -15,6 +18,12 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+// visible limits a query on the aliased pages table "p" to pages that are neither deleted, expired, taken down nor quarantined.
+// Expiry times may carry any offset, so they are compared as instants rather than text.
+func visible(db *gorm.DB) *gorm.DB {
+	return db.Where("p.deleted_at IS NULL AND p.taken_down_at IS NULL AND p.quarantined_at IS NULL AND (p.expires_at IS NULL OR unixepoch(p.expires_at) > ?)", time.Now().Unix())
+}
+
 // Create creates a new page and returns the created page
 func (r *repository) Create(ctx context.Context, page *Page) error {
 	return r.db.WithContext(ctx).Create(page).Error
@@ -45,6 +54,7 @@ func (r *repository) List(ctx context.Context, offset, limit int) ([]*PageList,
 	var pages []*PageList
 	err := r.db.WithContext(ctx).
 		Table("shared_content p").
+		Scopes(visible).
 		Select("p.id, p.slug, p.title, p.category_id, c.name as category_name, p.created_at").
 		Joins("LEFT JOIN categories c ON p.category_id = c.id").
 		Order("p.created_at DESC").
@@ -58,11 +68,74 @@ func (r *repository) List(ctx context.Context, offset, limit int) ([]*PageList,
 	return pages, nil
 }
 
+// Search retrieves a paginated list of pages matching a filter
+func (r *repository) Search(ctx context.Context, filter *PageFilter, offset, limit int) ([]*PageList, error) {
+	var pages []*PageList
+	err := r.filtered(ctx, filter).
+		Select("p.id, p.slug, p.title, p.category_id, c.name as category_name, p.created_at").
+		Joins("LEFT JOIN categories c ON p.category_id = c.id").
+		Order("p.created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&pages).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// CountSearch returns the total number of pages matching a filter
+func (r *repository) CountSearch(ctx context.Context, filter *PageFilter) (int64, error) {
+	var count int64
+	err := r.filtered(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// filtered builds a query over live pages matching a filter
+func (r *repository) filtered(ctx context.Context, filter *PageFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("shared_content p").
+		Scopes(visible)
+
+	if filter.CategoryID != nil {
+		query = query.Where("p.category_id = ?", *filter.CategoryID)
+	}
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM page_tags t WHERE t.page_id = p.id AND t.name = ?)", filter.Tag)
+	}
+	if filter.Query != "" {
+		query = query.Where("(p.title LIKE ? OR p.slug = ?)", "%"+filter.Query+"%", filter.Query)
+	}
+	if filter.OwnerID != nil {
+		query = query.Where("p.owner_id = ?", *filter.OwnerID)
+	}
+
+	return query
+}
+
+// GetMetadataBySlug retrieves page information without its content by slug
+func (r *repository) GetMetadataBySlug(ctx context.Context, slug string) (*PageMetadata, error) {
+	var metadata PageMetadata
+	err := r.db.WithContext(ctx).
+		Table("shared_content p").
+		Scopes(visible).
+		Select("p.id, p.slug, p.title, p.category_id, c.name as category_name, LENGTH(CAST(p.html_content AS BLOB)) as size, p.password_hash <> '' as password_protected, p.expires_at, p.live, p.created_at, p.updated_at").
+		Joins("LEFT JOIN categories c ON p.category_id = c.id").
+		Where("p.slug = ?", slug).
+		Take(&metadata).Error
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
 // ListByCategory retrieves a paginated list of pages filtered by category
 func (r *repository) ListByCategory(ctx context.Context, categoryID uint, offset, limit int) ([]*PageList, error) {
 	var pages []*PageList
 	err := r.db.WithContext(ctx).
 		Table("shared_content p").
+		Scopes(visible).
 		Select("p.id, p.slug, p.title, p.category_id, c.name as category_name, p.created_at").
 		Joins("LEFT JOIN categories c ON p.category_id = c.id").
 		Where("p.category_id = ?", categoryID).
@@ -80,14 +153,14 @@ func (r *repository) ListByCategory(ctx context.Context, categoryID uint, offset
 // Count returns the total number of pages
 func (r *repository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&Page{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Table("shared_content p").Scopes(visible).Count(&count).Error
 	return count, err
 }
 
 // CountByCategory returns the total number of pages in a category
 func (r *repository) CountByCategory(ctx context.Context, categoryID uint) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&Page{}).Where("category_id = ?", categoryID).Count(&count).Error
+	err := r.db.WithContext(ctx).Table("shared_content p").Scopes(visible).Where("p.category_id = ?", categoryID).Count(&count).Error
 	return count, err
 }
 
@@ -101,6 +174,16 @@ func (r *repository) Update(ctx context.Context, id uint, updates *PageUpdate) e
 	if updates.Title != nil {
 		updateMap["title"] = *updates.Title
 	}
+	if updates.CategoryID != nil {
+		if *updates.CategoryID == 0 {
+			updateMap["category_id"] = nil
+		} else {
+			updateMap["category_id"] = *updates.CategoryID
+		}
+	}
+	if updates.Live != nil {
+		updateMap["live"] = *updates.Live
+	}
 
 	if len(updateMap) == 0 {
 		return nil // No updates to perform
@@ -114,12 +197,387 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&Page{}, id).Error
 }
 
-// Exists checks if a slug already exists
+// Exists checks if a slug already exists, including on soft-deleted pages since slugs stay unique
 func (r *repository) Exists(ctx context.Context, slug string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&Page{}).Where("slug = ?", slug).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&Page{}).Where("slug = ?", slug).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
+
+// GetByIDWithDeleted retrieves a page by its ID, including soft-deleted pages
+func (r *repository) GetByIDWithDeleted(ctx context.Context, id uint) (*Page, error) {
+	var page Page
+	err := r.db.WithContext(ctx).Unscoped().First(&page, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Restore restores a soft-deleted page by ID
+func (r *repository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&Page{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// AddTags attaches tags to a page, ignoring tags it already has
+func (r *repository) AddTags(ctx context.Context, id uint, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	pageTags := make([]*PageTag, len(tags))
+	for i, tag := range tags {
+		pageTags[i] = &PageTag{PageID: id, Name: tag}
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&pageTags).Error
+}
+
+// GetTags retrieves the tags of several pages keyed by page ID
+func (r *repository) GetTags(ctx context.Context, ids []uint) (map[uint][]string, error) {
+	tags := make(map[uint][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	var pageTags []*PageTag
+	err := r.db.WithContext(ctx).
+		Where("page_id IN ?", ids).
+		Order("name ASC").
+		Find(&pageTags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range pageTags {
+		tags[tag.PageID] = append(tags[tag.PageID], tag.Name)
+	}
+	return tags, nil
+}
+
+// CategoryExists checks if a category exists
+func (r *repository) CategoryExists(ctx context.Context, categoryID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("categories").
+		Where("id = ? AND deleted_at IS NULL", categoryID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetCategoryIDByName retrieves the ID of a category by its name
+func (r *repository) GetCategoryIDByName(ctx context.Context, name string) (uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Table("categories").
+		Where("name = ? AND deleted_at IS NULL", name).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// HardDelete permanently deletes a page by ID, along with its tags
+func (r *repository) HardDelete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("page_id = ?", id).Delete(&PageTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("page_id = ?", id).Delete(&PageReport{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&Page{}, id).Error
+	})
+}
+
+// IncrementViews adds one to the view count of a page
+func (r *repository) IncrementViews(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&Page{}).Where("id = ?", id).
+		UpdateColumn("views", gorm.Expr("views + 1")).Error
+}
+
+// AdminList retrieves a paginated list of pages with their owners, including soft-deleted pages
+func (r *repository) AdminList(ctx context.Context, filter *AdminPageFilter, offset, limit int) ([]*AdminPageList, error) {
+	var pages []*AdminPageList
+	err := r.adminFiltered(ctx, filter).
+		Select("p.id, p.slug, p.title, p.owner_id, u.username AS owner_name, LENGTH(CAST(p.html_content AS BLOB)) AS size, "+
+			"p.views, p.creator_ip, p.created_at, p.deleted_at, p.taken_down_at, p.takedown_cause, p.scan_score, p.quarantined_at, "+
+			"(SELECT COUNT(*) FROM page_reports r WHERE r.page_id = p.id AND r.status = ?) AS open_reports", ReportOpen).
+		Order("p.created_at DESC, p.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&pages).Error
+	return pages, err
+}
+
+// AdminCount returns the number of pages matching a moderation filter
+func (r *repository) AdminCount(ctx context.Context, filter *AdminPageFilter) (int64, error) {
+	var count int64
+	err := r.adminFiltered(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// adminFiltered returns a query over all pages, joined with their owners, matching a moderation filter
+func (r *repository) adminFiltered(ctx context.Context, filter *AdminPageFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("shared_content p").
+		Joins("LEFT JOIN users u ON u.id = p.owner_id")
+
+	switch filter.Status {
+	case "deleted":
+		query = query.Where("p.deleted_at IS NOT NULL")
+	case "active":
+		query = query.Where("p.deleted_at IS NULL AND p.taken_down_at IS NULL AND p.quarantined_at IS NULL")
+	case "taken_down":
+		query = query.Where("p.taken_down_at IS NOT NULL")
+	case "quarantined":
+		query = query.Where("p.quarantined_at IS NOT NULL")
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("(p.title LIKE ? OR p.slug = ? OR u.username = ? OR p.creator_ip = ?)", like, filter.Query, filter.Query, filter.Query)
+	}
+
+	return query
+}
+
+// TakeDown marks a page as taken down for a reason, so that it is no longer served
+func (r *repository) TakeDown(ctx context.Context, id uint, cause ReportReason) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&Page{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"taken_down_at": time.Now().UTC(), "takedown_cause": cause}).Error
+}
+
+// Reinstate clears the takedown and quarantine of a page
+func (r *repository) Reinstate(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&Page{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"taken_down_at": nil, "takedown_cause": "", "quarantined_at": nil}).Error
+}
+
+// SetScan records the content scanner's score for a page, quarantining it when asked
+func (r *repository) SetScan(ctx context.Context, id uint, score int, quarantine bool) error {
+	updates := map[string]any{"scan_score": score}
+	if quarantine {
+		updates["quarantined_at"] = time.Now().UTC()
+	}
+	return r.db.WithContext(ctx).Model(&Page{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
+
+// Release clears the quarantine of a page
+func (r *repository) Release(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&Page{}).Where("id = ?", id).
+		UpdateColumn("quarantined_at", nil).Error
+}
+
+// CreateReport stores a report about a page
+func (r *repository) CreateReport(ctx context.Context, report *PageReport) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// HasOpenReport checks if a page already has a report waiting for review from an address
+func (r *repository) HasOpenReport(ctx context.Context, pageID uint, reporterIP string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&PageReport{}).
+		Where("page_id = ? AND reporter_ip = ? AND status = ?", pageID, reporterIP, ReportOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasOpenScanReport checks if a page already has a report from the content scanner waiting for review
+func (r *repository) HasOpenScanReport(ctx context.Context, pageID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&PageReport{}).
+		Where("page_id = ? AND source = ? AND status = ?", pageID, ReportFromScanner, ReportOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetReport retrieves a report by its ID
+func (r *repository) GetReport(ctx context.Context, id uint) (*PageReport, error) {
+	var report PageReport
+	err := r.db.WithContext(ctx).First(&report, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReports retrieves a paginated list of reports with the pages they are about
+func (r *repository) ListReports(ctx context.Context, filter *ReportFilter, offset, limit int) ([]*ReportList, error) {
+	var reports []*ReportList
+	err := r.filteredReports(ctx, filter).
+		Select("r.id, r.page_id, p.slug, p.title, r.source, r.reason, r.details, r.reporter_email, ru.username AS reporter_name, " +
+			"r.reporter_ip, r.user_agent, r.status, au.username AS resolver_name, r.resolved_at, r.created_at, " +
+			"p.taken_down_at AS page_taken_down_at, p.quarantined_at AS page_quarantined_at, p.deleted_at AS page_deleted_at").
+		Joins("LEFT JOIN users ru ON ru.id = r.reporter_id").
+		Joins("LEFT JOIN users au ON au.id = r.resolved_by_id").
+		Order("r.created_at DESC, r.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+// CountReports returns the number of reports matching a filter
+func (r *repository) CountReports(ctx context.Context, filter *ReportFilter) (int64, error) {
+	var count int64
+	err := r.filteredReports(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// filteredReports returns a query over reports, joined with their pages, matching a filter
+func (r *repository) filteredReports(ctx context.Context, filter *ReportFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Table("page_reports r").
+		Joins("JOIN shared_content p ON p.id = r.page_id")
+
+	switch filter.Status {
+	case "open":
+		query = query.Where("r.status = ?", ReportOpen)
+	case "resolved":
+		query = query.Where("r.status <> ?", ReportOpen)
+	}
+
+	return query
+}
+
+// ResolveReport sets the status of an open report, recording who resolved it
+func (r *repository) ResolveReport(ctx context.Context, id uint, status ReportStatus, resolverID *uint) error {
+	result := r.db.WithContext(ctx).Model(&PageReport{}).
+		Where("id = ? AND status = ?", id, ReportOpen).
+		UpdateColumns(map[string]any{"status": status, "resolved_by_id": resolverID, "resolved_at": time.Now().UTC()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResolvePageReports sets the status of every open report about a page, recording who resolved them
+func (r *repository) ResolvePageReports(ctx context.Context, pageID uint, status ReportStatus, resolverID *uint) error {
+	return r.db.WithContext(ctx).Model(&PageReport{}).
+		Where("page_id = ? AND status = ?", pageID, ReportOpen).
+		UpdateColumns(map[string]any{"status": status, "resolved_by_id": resolverID, "resolved_at": time.Now().UTC()}).Error
+}
+
+// DismissScanReports dismisses every open report about a page filed by the content scanner
+func (r *repository) DismissScanReports(ctx context.Context, pageID uint) error {
+	return r.db.WithContext(ctx).Model(&PageReport{}).
+		Where("page_id = ? AND source = ? AND status = ?", pageID, ReportFromScanner, ReportOpen).
+		UpdateColumns(map[string]any{"status": ReportDismissed, "resolved_at": time.Now().UTC()}).Error
+}
+
+// GetUserIDByName retrieves the ID of a user by their username
+func (r *repository) GetUserIDByName(ctx context.Context, username string) (uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Where("username = ? AND deleted_at IS NULL", username).
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// CategoryOptions retrieves every category, ordered by name
+func (r *repository) CategoryOptions(ctx context.Context) ([]*CategoryOption, error) {
+	var options []*CategoryOption
+	err := r.db.WithContext(ctx).
+		Table("categories").
+		Select("id, name").
+		Where("deleted_at IS NULL").
+		Order("name").
+		Find(&options).Error
+	return options, err
+}
+
+// ListForExport retrieves every page that has not been deleted, quarantined or
+// taken down, including its content
+func (r *repository) ListForExport(ctx context.Context) ([]*PageExport, error) {
+	var pages []*PageExport
+	err := r.db.WithContext(ctx).
+		Table("shared_content p").
+		Select("p.id, p.slug, p.title, c.name as category_name, u.username as owner_name, p.html_content, p.edit_token_hash, p.password_hash, p.expires_at, p.live, p.created_at, p.updated_at").
+		Joins("LEFT JOIN categories c ON p.category_id = c.id").
+		Joins("LEFT JOIN users u ON p.owner_id = u.id AND u.deleted_at IS NULL").
+		Where("p.deleted_at IS NULL AND p.quarantined_at IS NULL AND p.taken_down_at IS NULL").
+		Order("p.id ASC").
+		Find(&pages).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// Purge permanently deletes pages soft-deleted before a time, along with their tags
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Timestamps are stored with the offset of the host's time zone, so they are compared as instants rather than text
+		deleted := tx.Unscoped().Model(&Page{}).Select("id").Where("deleted_at IS NOT NULL AND unixepoch(deleted_at) < ?", before.Unix())
+
+		if err := tx.Where("page_id IN (?)", deleted).Delete(&PageTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("page_id IN (?)", deleted).Delete(&PageReport{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND unixepoch(deleted_at) < ?", before.Unix()).Delete(&Page{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// DeleteExpired soft deletes pages whose expiry has passed. Expiry is compared in
+// whole seconds, so a page is never moved to the trash before it expires.
+func (r *repository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at IS NOT NULL AND unixepoch(expires_at) < ?", now.Unix()).Delete(&Page{})
+	return result.RowsAffected, result.Error
+}
+
+// Stats returns aggregate statistics about stored pages
+func (r *repository) Stats(ctx context.Context) (*PageStats, error) {
+	var stats PageStats
+	now := time.Now().Unix()
+	err := r.db.WithContext(ctx).
+		Table("shared_content p").
+		Select(`COALESCE(SUM(CASE WHEN p.deleted_at IS NULL AND (p.expires_at IS NULL OR unixepoch(p.expires_at) > ?) THEN 1 ELSE 0 END), 0) AS active,
+			COALESCE(SUM(CASE WHEN p.deleted_at IS NULL AND unixepoch(p.expires_at) <= ? THEN 1 ELSE 0 END), 0) AS expired,
+			COALESCE(SUM(CASE WHEN p.deleted_at IS NOT NULL THEN 1 ELSE 0 END), 0) AS deleted,
+			COALESCE(SUM(CASE WHEN p.deleted_at IS NULL AND p.password_hash <> '' THEN 1 ELSE 0 END), 0) AS password_protected,
+			COALESCE(SUM(CASE WHEN p.deleted_at IS NULL AND p.live THEN 1 ELSE 0 END), 0) AS live,
+			COALESCE(SUM(LENGTH(CAST(p.html_content AS BLOB))), 0) AS content_bytes,
+			(SELECT COUNT(DISTINCT t.name) FROM page_tags t) AS tags`, now, now).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// WithTransaction runs fn with a repository bound to a single database transaction
+func (r *repository) WithTransaction(ctx context.Context, fn func(repo Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&repository{db: tx})
+	})
+}
@@ -2,21 +2,95 @@ package page
 
 import (
 	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/mail"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"sharer/internal/auth"
+	"sharer/internal/logging"
+	"sharer/internal/metrics"
+	"sharer/internal/scanner"
 )
 
+// ErrInvalidEditToken is returned when a page is modified without its edit token
+var ErrInvalidEditToken = errors.New("invalid edit token")
+
+// ErrInvalidPassword is returned when a protected page is unlocked with a wrong password
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrNotDeleted is returned when restoring a page that has not been deleted
+var ErrNotDeleted = errors.New("page is not deleted")
+
+// ErrTakenDown is returned when viewing a page that was taken down after a report
+var ErrTakenDown = errors.New("page has been taken down")
+
+// ErrNotTakenDown is returned when reinstating a page that is neither taken down nor quarantined
+var ErrNotTakenDown = errors.New("page is not taken down")
+
+// ErrQuarantined is returned when viewing a page the content scanner holds for review
+var ErrQuarantined = errors.New("page is quarantined")
+
+// ErrReportResolved is returned when acting on a report that has already been resolved
+var ErrReportResolved = errors.New("report is already resolved")
+
+// ErrCategoryNotFound is returned when a category reference does not match any category
+var ErrCategoryNotFound = errors.New("category not found")
+
+// maxBulkPages is the maximum number of pages a single bulk operation may touch
+const maxBulkPages = 500
+
+// maxTagLength is the maximum length of a single tag
+const maxTagLength = 64
+
+// maxReportDetails is the maximum length of the details given with a report
+const maxReportDetails = 2000
+
+// validSlug matches slugs that can be kept when importing pages
+var validSlug = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Config holds page service settings
+type Config struct {
+	// SlugLength is the length of generated slugs, 8 when zero
+	SlugLength int
+	// MaxContentBytes is the largest page content accepted, unlimited when zero
+	MaxContentBytes int64
+	// LiveReload allows live pages that reload open viewers when updated
+	LiveReload bool
+	// Passwords allows password-protected pages
+	Passwords bool
+	// ReportBanner adds a link to the report form to every shared page
+	ReportBanner bool
+	// Scanner scores new content for phishing and malware, disabled when nil
+	Scanner *scanner.Scanner
+}
+
 // service implements the Service interface
 type service struct {
-	repo Repository
+	repo   Repository
+	config Config
+	events *broadcaster
 }
 
 // NewService creates a new page service
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, config Config) Service {
+	if config.SlugLength == 0 {
+		config.SlugLength = 8
+	}
+	return &service{repo: repo, config: config, events: newBroadcaster()}
 }
 
 // CreatePage creates a new shared page
@@ -25,33 +99,174 @@ func (s *service) CreatePage(ctx context.Context, req *PageCreate) (*PageRespons
 	if strings.TrimSpace(req.HTMLContent) == "" {
 		return &PageResponse{Error: "No HTML content provided"}, nil
 	}
+	content, contentErr := s.validateContent(req.HTMLContent)
+	if contentErr != nil {
+		return &PageResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+	}
+	if req.Password != "" && !s.config.Passwords {
+		return &PageResponse{Error: "Password protection is disabled"}, nil
+	}
+	scan, contentErr := s.scanContent(content)
+	if contentErr != nil {
+		return &PageResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+	}
+
+	// Validate category if provided
+	if req.CategoryID != nil {
+		exists, err := s.repo.CategoryExists(ctx, *req.CategoryID)
+		if err != nil {
+			logging.FromContext(ctx).Error("checking category failed", "error", err)
+			return &PageResponse{Error: "Error checking category"}, err
+		}
+		if !exists {
+			return &PageResponse{Error: "Category not found"}, nil
+		}
+	}
 
 	// Generate unique slug
 	slug, err := s.GenerateUniqueSlug(ctx)
 	if err != nil {
+		logging.FromContext(ctx).Error("generating unique slug failed", "error", err)
 		return &PageResponse{Error: "Error generating unique slug"}, err
 	}
 
 	// Extract title if not provided
 	title := req.Title
 	if title == "" {
-		title = s.ExtractTitle(req.HTMLContent)
+		title = s.ExtractTitle(content)
+	}
+
+	// Validate expiry if provided
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return &PageResponse{Error: "Expiry must be in the future"}, nil
+		}
+		utc := req.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	// Hash the viewing password if provided
+	var passwordHash string
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			logging.FromContext(ctx).Error("hashing password failed", "error", err)
+			return &PageResponse{Error: "Error hashing password"}, err
+		}
+		passwordHash = string(hash)
+	}
+
+	// Generate the token that authorizes later updates
+	editToken, err := generateEditToken()
+	if err != nil {
+		logging.FromContext(ctx).Error("generating edit token failed", "error", err)
+		return &PageResponse{Error: "Error generating edit token"}, err
 	}
 
 	// Create page model
 	page := &Page{
-		Slug:        slug,
-		HTMLContent: req.HTMLContent,
-		Title:       title,
-		CategoryID:  req.CategoryID,
+		Slug:          slug,
+		HTMLContent:   content,
+		Title:         title,
+		CategoryID:    req.CategoryID,
+		EditTokenHash: hashEditToken(editToken),
+		PasswordHash:  passwordHash,
+		ExpiresAt:     expiresAt,
+		Live:          req.Live && s.config.LiveReload,
+		OwnerID:       requestOwner(ctx),
+		CreatorIP:     req.CreatorIP,
+	}
+	if scan != nil {
+		page.ScanScore = scan.Score
+		if scan.Verdict == scanner.Quarantine {
+			now := time.Now().UTC()
+			page.QuarantinedAt = &now
+		}
 	}
 
 	// Save to repository
 	if err := s.repo.Create(ctx, page); err != nil {
+		logging.FromContext(ctx).Error("saving content failed", "error", err)
 		return &PageResponse{Error: "Error saving content"}, err
 	}
+	s.reportScan(ctx, page.ID, scan)
+
+	return &PageResponse{
+		URL:         "/shared/" + slug,
+		Slug:        slug,
+		EditToken:   editToken,
+		Quarantined: page.QuarantinedAt != nil,
+	}, nil
+}
+
+// scanContent runs content past the content scanner, returning nil when scanning
+// is disabled and a ContentError when the content is blocked
+func (s *service) scanContent(content string) (*scanner.Result, *ContentError) {
+	if s.config.Scanner == nil {
+		return nil, nil
+	}
+
+	result := s.config.Scanner.Scan(content)
+	metrics.ContentScans.WithLabelValues(string(result.Verdict)).Inc()
+	if result.Verdict == scanner.Block {
+		return nil, &ContentError{
+			Code:    ContentBlocked,
+			Message: "Content was blocked because it looks like phishing or malware",
+		}
+	}
+	return result, nil
+}
+
+// reportScan files a report for the moderation queue when the content scanner
+// flagged or quarantined a page. The page is already saved, so failures are
+// logged rather than returned.
+func (s *service) reportScan(ctx context.Context, pageID uint, result *scanner.Result) {
+	if result == nil || (result.Verdict != scanner.Flag && result.Verdict != scanner.Quarantine) {
+		return
+	}
+
+	// A page already awaiting review keeps its open scanner report
+	duplicate, err := s.repo.HasOpenScanReport(ctx, pageID)
+	if err != nil {
+		logging.FromContext(ctx).Error("checking reports failed", "error", err)
+		return
+	}
+	if duplicate {
+		return
+	}
 
-	return &PageResponse{URL: "/shared/" + slug}, nil
+	reason := ReportOther
+	switch result.Category() {
+	case scanner.CategoryPhishing:
+		reason = ReportPhishing
+	case scanner.CategoryMalware:
+		reason = ReportMalware
+	}
+	details := result.Summary()
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		details = string([]rune(details)[:maxReportDetails])
+	}
+
+	report := &PageReport{
+		PageID:  pageID,
+		Source:  ReportFromScanner,
+		Reason:  reason,
+		Details: details,
+		Status:  ReportOpen,
+	}
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		logging.FromContext(ctx).Error("saving scanner report failed", "error", err)
+	}
+}
+
+// dismissScanReports closes the scanner's open reports about a page whose
+// content has been edited and now scans clean. Failures are logged, as the
+// page is already saved.
+func (s *service) dismissScanReports(ctx context.Context, pageID uint) {
+	if err := s.repo.DismissScanReports(ctx, pageID); err != nil {
+		logging.FromContext(ctx).Error("dismissing scanner reports failed", "error", err)
+	}
 }
 
 // GetPageBySlug retrieves a page by its slug for viewing
@@ -61,16 +276,564 @@ func (s *service) GetPageBySlug(ctx context.Context, slug string) (*PageDetail,
 		return nil, err
 	}
 
+	// Expired pages are treated as missing
+	if page.ExpiresAt != nil && !page.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if page.TakenDownAt != nil {
+		return nil, ErrTakenDown
+	}
+	// Administrators can still open a quarantined page to review it
+	if page.QuarantinedAt != nil && !isAdmin(ctx) {
+		return nil, ErrQuarantined
+	}
+
+	return &PageDetail{
+		ID:                page.ID,
+		Slug:              page.Slug,
+		HTMLContent:       page.HTMLContent,
+		Title:             page.Title,
+		CategoryID:        page.CategoryID,
+		PasswordProtected: page.PasswordHash != "",
+		ExpiresAt:         page.ExpiresAt,
+		Live:              page.Live && s.config.LiveReload,
+		CreatedAt:         page.CreatedAt,
+		UpdatedAt:         page.UpdatedAt,
+		ReportBanner:      s.config.ReportBanner,
+	}, nil
+}
+
+// UnlockPage retrieves a password-protected page by its slug after checking the password
+func (s *service) UnlockPage(ctx context.Context, slug, password string) (*PageDetail, error) {
+	page, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.ExpiresAt != nil && !page.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if page.TakenDownAt != nil {
+		return nil, ErrTakenDown
+	}
+	// Administrators can still open a quarantined page to review it
+	if page.QuarantinedAt != nil && !isAdmin(ctx) {
+		return nil, ErrQuarantined
+	}
+
+	if page.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(page.PasswordHash), []byte(password)); err != nil {
+			return nil, ErrInvalidPassword
+		}
+	}
+
 	return &PageDetail{
-		ID:          page.ID,
-		Slug:        page.Slug,
-		HTMLContent: page.HTMLContent,
-		Title:       page.Title,
-		CreatedAt:   page.CreatedAt,
-		UpdatedAt:   page.UpdatedAt,
+		ID:                page.ID,
+		Slug:              page.Slug,
+		HTMLContent:       page.HTMLContent,
+		Title:             page.Title,
+		CategoryID:        page.CategoryID,
+		PasswordProtected: page.PasswordHash != "",
+		ExpiresAt:         page.ExpiresAt,
+		Live:              page.Live && s.config.LiveReload,
+		CreatedAt:         page.CreatedAt,
+		UpdatedAt:         page.UpdatedAt,
+		ReportBanner:      s.config.ReportBanner,
 	}, nil
 }
 
+// GetPageMetadata retrieves page information without its content by slug
+func (s *service) GetPageMetadata(ctx context.Context, slug string) (*PageMetadata, error) {
+	metadata, err := s.repo.GetMetadataBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.GetTags(ctx, []uint{metadata.ID})
+	if err != nil {
+		return nil, err
+	}
+	metadata.Tags = tags[metadata.ID]
+
+	return metadata, nil
+}
+
+// SearchPages retrieves a paginated list of pages matching a filter
+func (s *service) SearchPages(ctx context.Context, filter *PageFilter, page, pageSize int) ([]*PageList, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	pages, err := s.repo.Search(ctx, filter, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.attachTags(ctx, pages); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountSearch(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pages, total, nil
+}
+
+// UpdatePage updates a page identified by slug after checking its edit token.
+// Expired pages are treated as missing, so editing cannot bring one back.
+func (s *service) UpdatePage(ctx context.Context, slug, editToken string, req *PageUpdate) (*PageMetadataResponse, error) {
+	page, err := s.authorizedPage(ctx, slug, editToken)
+	if err != nil {
+		return nil, err
+	}
+	if page.ExpiresAt != nil && !page.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// Validate HTML content if provided
+	if req.HTMLContent != nil && strings.TrimSpace(*req.HTMLContent) == "" {
+		return &PageMetadataResponse{Error: "HTML content cannot be empty"}, nil
+	}
+	var scan *scanner.Result
+	if req.HTMLContent != nil {
+		content, contentErr := s.validateContent(*req.HTMLContent)
+		if contentErr != nil {
+			return &PageMetadataResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+		}
+		if scan, contentErr = s.scanContent(content); contentErr != nil {
+			return &PageMetadataResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+		}
+		req.HTMLContent = &content
+	}
+
+	// Re-extract the title when it is cleared
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			content := page.HTMLContent
+			if req.HTMLContent != nil {
+				content = *req.HTMLContent
+			}
+			title = s.ExtractTitle(content)
+		}
+		req.Title = &title
+	}
+
+	if req.Live != nil && *req.Live && !s.config.LiveReload {
+		return &PageMetadataResponse{Error: "Live reload is disabled"}, nil
+	}
+
+	// Validate category if provided; 0 removes the page from its category
+	if req.CategoryID != nil && *req.CategoryID != 0 {
+		exists, err := s.repo.CategoryExists(ctx, *req.CategoryID)
+		if err != nil {
+			logging.FromContext(ctx).Error("checking category failed", "error", err)
+			return &PageMetadataResponse{Error: "Error checking category"}, err
+		}
+		if !exists {
+			return &PageMetadataResponse{Error: "Category not found"}, nil
+		}
+	}
+
+	quarantined := page.QuarantinedAt != nil
+	err = s.repo.WithTransaction(ctx, func(repo Repository) error {
+		if err := repo.Update(ctx, page.ID, req); err != nil {
+			return err
+		}
+		if scan == nil {
+			return nil
+		}
+		quarantine := scan.Verdict == scanner.Quarantine && !quarantined
+		return repo.SetScan(ctx, page.ID, scan.Score, quarantine)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("updating page failed", "error", err)
+		return &PageMetadataResponse{Error: "Error updating page"}, err
+	}
+	s.reportScan(ctx, page.ID, scan)
+	// A quarantined page stays hidden until reviewed, so its report is kept open
+	if scan != nil && scan.Verdict == scanner.Allow && !quarantined {
+		s.dismissScanReports(ctx, page.ID)
+	}
+	s.events.publish(slug)
+
+	// Quarantined pages are hidden until reviewed, so there is no metadata to show
+	if quarantined || (scan != nil && scan.Verdict == scanner.Quarantine) {
+		return &PageMetadataResponse{Quarantined: true}, nil
+	}
+
+	metadata, err := s.GetPageMetadata(ctx, slug)
+	if err != nil {
+		logging.FromContext(ctx).Error("retrieving updated page failed", "error", err)
+		return &PageMetadataResponse{Error: "Error retrieving updated page"}, err
+	}
+
+	return &PageMetadataResponse{Page: metadata}, nil
+}
+
+// DeletePage soft deletes a page identified by slug after checking its edit token
+func (s *service) DeletePage(ctx context.Context, slug, editToken string) error {
+	page, err := s.authorizedPage(ctx, slug, editToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, page.ID); err != nil {
+		return err
+	}
+	s.events.publish(slug)
+	return nil
+}
+
+// Subscribe registers for change notifications on a page and returns a function that unregisters
+func (s *service) Subscribe(slug string) (<-chan struct{}, func()) {
+	return s.events.subscribe(slug)
+}
+
+// CloseSubscriptions closes every change subscription so that open event streams end
+func (s *service) CloseSubscriptions() {
+	s.events.close()
+}
+
+// authorizedPage retrieves a page by slug and checks that the request may change
+// it: the request is made by the page's owner, or editToken matches the page
+func (s *service) authorizedPage(ctx context.Context, slug, editToken string) (*Page, error) {
+	page, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if canManage(ctx, page) {
+		return page, nil
+	}
+	if page.EditTokenHash == "" || editToken == "" ||
+		subtle.ConstantTimeCompare([]byte(page.EditTokenHash), []byte(hashEditToken(editToken))) != 1 {
+		return nil, ErrInvalidEditToken
+	}
+
+	return page, nil
+}
+
+// GetOwnedPage retrieves a page by slug for its owner, hiding pages owned by anyone else
+func (s *service) GetOwnedPage(ctx context.Context, slug string) (*PageDetail, error) {
+	page, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !ownsPage(ctx, page) {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &PageDetail{
+		ID:                page.ID,
+		Slug:              page.Slug,
+		HTMLContent:       page.HTMLContent,
+		Title:             page.Title,
+		CategoryID:        page.CategoryID,
+		PasswordProtected: page.PasswordHash != "",
+		ExpiresAt:         page.ExpiresAt,
+		Live:              page.Live,
+		CreatedAt:         page.CreatedAt,
+		UpdatedAt:         page.UpdatedAt,
+	}, nil
+}
+
+// GetCategoryOptions retrieves every category a page can be moved to
+func (s *service) GetCategoryOptions(ctx context.Context) ([]*CategoryOption, error) {
+	return s.repo.CategoryOptions(ctx)
+}
+
+// requestOwner returns the ID of the user a request is authenticated as, or nil for anonymous requests
+func requestOwner(ctx context.Context) *uint {
+	if identity := auth.FromContext(ctx); identity != nil {
+		id := identity.UserID
+		return &id
+	}
+	return nil
+}
+
+// ownsPage reports whether the request is made by the owner of a page
+func ownsPage(ctx context.Context, page *Page) bool {
+	identity := auth.FromContext(ctx)
+	return identity != nil && page.OwnerID != nil && *page.OwnerID == identity.UserID
+}
+
+// canManage reports whether the request is made by the owner of a page or by an administrator
+func canManage(ctx context.Context, page *Page) bool {
+	return ownsPage(ctx, page) || isAdmin(ctx)
+}
+
+// isAdmin reports whether the request is made by an administrator
+func isAdmin(ctx context.Context) bool {
+	identity := auth.FromContext(ctx)
+	return identity != nil && identity.IsAdmin()
+}
+
+// RecordView counts a view of a page
+func (s *service) RecordView(ctx context.Context, id uint) error {
+	return s.repo.IncrementViews(ctx, id)
+}
+
+// AdminListPages retrieves a paginated list of pages for moderation, including soft-deleted pages
+func (s *service) AdminListPages(ctx context.Context, filter *AdminPageFilter, page, pageSize int) ([]*AdminPageList, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	pages, err := s.repo.AdminList(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.AdminCount(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return pages, total, nil
+}
+
+// HardDeletePage permanently deletes a page by ID, including a soft-deleted one
+func (s *service) HardDeletePage(ctx context.Context, id uint) error {
+	page, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.HardDelete(ctx, id); err != nil {
+		return err
+	}
+	s.events.publish(page.Slug)
+	return nil
+}
+
+// RestorePage restores a soft-deleted page by ID
+func (s *service) RestorePage(ctx context.Context, id uint) error {
+	page, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !page.DeletedAt.Valid {
+		return ErrNotDeleted
+	}
+
+	return s.repo.Restore(ctx, id)
+}
+
+// ReportPage records a visitor's report about a page for moderation. A second
+// report about the same page from the same address is accepted but not stored
+// while the first one is still open.
+func (s *service) ReportPage(ctx context.Context, slug string, req *ReportCreate) (*ReportResponse, error) {
+	page, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if page.ExpiresAt != nil && !page.ExpiresAt.After(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if page.TakenDownAt != nil {
+		return nil, ErrTakenDown
+	}
+	if page.QuarantinedAt != nil {
+		return nil, ErrQuarantined
+	}
+
+	if !slices.Contains(ReportReasons, req.Reason) {
+		return &ReportResponse{Error: "Choose a reason for your report"}, nil
+	}
+	details := strings.TrimSpace(req.Details)
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		return &ReportResponse{Error: fmt.Sprintf("Details must be at most %d characters", maxReportDetails)}, nil
+	}
+	if req.Reason == ReportOther && details == "" {
+		return &ReportResponse{Error: "Describe what is wrong with the page"}, nil
+	}
+	var email string
+	if strings.TrimSpace(req.Email) != "" {
+		address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+		if err != nil || len(address.Address) > 255 {
+			return &ReportResponse{Error: "Email address is not valid"}, nil
+		}
+		email = address.Address
+	}
+
+	duplicate, err := s.repo.HasOpenReport(ctx, page.ID, req.ReporterIP)
+	if err != nil {
+		logging.FromContext(ctx).Error("checking reports failed", "error", err)
+		return &ReportResponse{Error: "Error saving report"}, err
+	}
+	if duplicate {
+		return &ReportResponse{}, nil
+	}
+
+	userAgent := req.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = strings.ToValidUTF8(userAgent[:255], "")
+	}
+	report := &PageReport{
+		PageID:        page.ID,
+		Reason:        req.Reason,
+		Details:       details,
+		ReporterEmail: email,
+		ReporterID:    requestOwner(ctx),
+		ReporterIP:    req.ReporterIP,
+		UserAgent:     userAgent,
+		Status:        ReportOpen,
+	}
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		logging.FromContext(ctx).Error("saving report failed", "error", err)
+		return &ReportResponse{Error: "Error saving report"}, err
+	}
+
+	return &ReportResponse{}, nil
+}
+
+// ListReports retrieves a paginated list of reports for the moderation queue
+func (s *service) ListReports(ctx context.Context, filter *ReportFilter, page, pageSize int) ([]*ReportList, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	reports, err := s.repo.ListReports(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.CountReports(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+// DismissReport closes a report without acting on it. Dismissing the content
+// scanner's report about a quarantined page releases the page.
+func (s *service) DismissReport(ctx context.Context, id uint) error {
+	report, err := s.repo.GetReport(ctx, id)
+	if err != nil {
+		return err
+	}
+	if report.Status != ReportOpen {
+		return ErrReportResolved
+	}
+	if report.Source != ReportFromScanner {
+		return s.repo.ResolveReport(ctx, id, ReportDismissed, requestOwner(ctx))
+	}
+
+	page, err := s.repo.GetByIDWithDeleted(ctx, report.PageID)
+	if err != nil {
+		return err
+	}
+	err = s.repo.WithTransaction(ctx, func(repo Repository) error {
+		if err := repo.ResolveReport(ctx, id, ReportDismissed, requestOwner(ctx)); err != nil {
+			return err
+		}
+		if page.QuarantinedAt == nil {
+			return nil
+		}
+		return repo.Release(ctx, page.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(page.Slug)
+	return nil
+}
+
+// TakeDownReportedPage takes down the page a report is about and closes every open
+// report about it. Open viewers of a live page reload and see the takedown notice.
+func (s *service) TakeDownReportedPage(ctx context.Context, reportID uint) error {
+	report, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status != ReportOpen {
+		return ErrReportResolved
+	}
+	page, err := s.repo.GetByIDWithDeleted(ctx, report.PageID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.WithTransaction(ctx, func(repo Repository) error {
+		if page.TakenDownAt == nil {
+			if err := repo.TakeDown(ctx, page.ID, report.Reason); err != nil {
+				return err
+			}
+		}
+		return repo.ResolvePageReports(ctx, page.ID, ReportActioned, requestOwner(ctx))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(page.Slug)
+	return nil
+}
+
+// ReinstatePage serves a taken-down or quarantined page again
+func (s *service) ReinstatePage(ctx context.Context, id uint) error {
+	page, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return err
+	}
+	if page.TakenDownAt == nil && page.QuarantinedAt == nil {
+		return ErrNotTakenDown
+	}
+
+	if err := s.repo.Reinstate(ctx, id); err != nil {
+		return err
+	}
+	s.events.publish(page.Slug)
+	return nil
+}
+
+// ResolveCategory resolves a category given by ID or by name
+func (s *service) ResolveCategory(ctx context.Context, ref string) (*uint, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, nil
+	}
+
+	if parsed, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		id := uint(parsed)
+		exists, err := s.repo.CategoryExists(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return &id, nil
+		}
+	}
+
+	id, err := s.repo.GetCategoryIDByName(ctx, ref)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
 // GetPagesList retrieves a paginated list of pages
 func (s *service) GetPagesList(ctx context.Context, page, pageSize int) ([]*PageList, int64, error) {
 	if page < 1 {
@@ -87,6 +850,10 @@ func (s *service) GetPagesList(ctx context.Context, page, pageSize int) ([]*Page
 		return nil, 0, err
 	}
 
+	if err := s.attachTags(ctx, pages); err != nil {
+		return nil, 0, err
+	}
+
 	total, err := s.repo.Count(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -111,6 +878,10 @@ func (s *service) GetPagesByCategory(ctx context.Context, categoryID uint, page,
 		return nil, 0, err
 	}
 
+	if err := s.attachTags(ctx, pages); err != nil {
+		return nil, 0, err
+	}
+
 	total, err := s.repo.CountByCategory(ctx, categoryID)
 	if err != nil {
 		return nil, 0, err
@@ -119,11 +890,306 @@ func (s *service) GetPagesByCategory(ctx context.Context, categoryID uint, page,
 	return pages, total, nil
 }
 
+// BulkUpdate applies an operation to several pages in a single transaction.
+// Pages that cannot be processed are reported individually; database errors roll back the whole operation.
+func (s *service) BulkUpdate(ctx context.Context, req *PageBulk) (*PageBulkResponse, error) {
+	response := &PageBulkResponse{Action: req.Action}
+
+	if len(req.PageIDs) == 0 {
+		response.Error = "No pages selected"
+		return response, nil
+	}
+	if len(req.PageIDs) > maxBulkPages {
+		response.Error = fmt.Sprintf("Cannot update more than %d pages at once", maxBulkPages)
+		return response, nil
+	}
+
+	var tags []string
+	switch req.Action {
+	case BulkActionMove:
+		if req.CategoryID == nil || *req.CategoryID == 0 {
+			response.Error = "Target category is required"
+			return response, nil
+		}
+		exists, err := s.repo.CategoryExists(ctx, *req.CategoryID)
+		if err != nil {
+			response.Error = "Error checking category"
+			return response, err
+		}
+		if !exists {
+			response.Error = "Target category not found"
+			return response, nil
+		}
+	case BulkActionTag:
+		tags = normalizeTags(req.Tags)
+		if len(tags) == 0 {
+			response.Error = "At least one tag is required"
+			return response, nil
+		}
+	case BulkActionDelete, BulkActionRestore:
+	default:
+		response.Error = "Invalid bulk action"
+		return response, nil
+	}
+
+	err := s.repo.WithTransaction(ctx, func(repo Repository) error {
+		response.Results = make([]*PageBulkResult, 0, len(req.PageIDs))
+		seen := make(map[uint]bool, len(req.PageIDs))
+
+		for _, id := range req.PageIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			result := &PageBulkResult{ID: id}
+			response.Results = append(response.Results, result)
+
+			page, err := repo.GetByIDWithDeleted(ctx, id)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					result.Error = "Page not found"
+					continue
+				}
+				return err
+			}
+
+			// Pages can only be changed by their owner or an administrator, so
+			// pages created without an account are left to administrators
+			if !canManage(ctx, page) {
+				if page.OwnerID == nil {
+					result.Error = "Only administrators can change pages without an owner"
+				} else {
+					result.Error = "Page belongs to another user"
+				}
+				continue
+			}
+
+			deleted := page.DeletedAt.Valid
+			if deleted && req.Action != BulkActionRestore {
+				result.Error = "Page is deleted"
+				continue
+			}
+
+			switch req.Action {
+			case BulkActionMove:
+				err = repo.Update(ctx, id, &PageUpdate{CategoryID: req.CategoryID})
+			case BulkActionTag:
+				err = repo.AddTags(ctx, id, tags)
+			case BulkActionDelete:
+				err = repo.Delete(ctx, id)
+			case BulkActionRestore:
+				if !deleted {
+					result.Error = "Page is not deleted"
+					continue
+				}
+				err = repo.Restore(ctx, id)
+			}
+			if err != nil {
+				return err
+			}
+
+			result.Success = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("applying bulk operation failed", "error", err)
+		return &PageBulkResponse{Action: req.Action, Error: "Error applying bulk operation"}, err
+	}
+
+	for _, result := range response.Results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+// attachTags loads the tags of the listed pages with a single query
+func (s *service) attachTags(ctx context.Context, pages []*PageList) error {
+	ids := make([]uint, len(pages))
+	for i, p := range pages {
+		ids[i] = p.ID
+	}
+
+	tags, err := s.repo.GetTags(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pages {
+		p.Tags = tags[p.ID]
+	}
+	return nil
+}
+
+// normalizeTags splits comma-separated tags, lowercases them and drops blanks and duplicates
+func normalizeTags(raw []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	for _, value := range raw {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			if runes := []rune(tag); len(runes) > maxTagLength {
+				tag = string(runes[:maxTagLength])
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// ExportPages retrieves every page that has not been deleted, quarantined or
+// taken down, with its content and tags. Pages held back by moderation are left
+// out rather than exported without that state.
+func (s *service) ExportPages(ctx context.Context) ([]*PageExport, error) {
+	pages, err := s.repo.ListForExport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(pages))
+	for i, p := range pages {
+		ids[i] = p.ID
+	}
+
+	tags, err := s.repo.GetTags(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pages {
+		p.Tags = tags[p.ID]
+	}
+
+	return pages, nil
+}
+
+// ImportPage creates a page from an export, keeping its slug when it is still free.
+// The content is validated and scanned like newly shared content, so an archive
+// cannot bring in pages that would be rejected, blocked or quarantined otherwise.
+// The page is given back to its owner when a user with the exported username exists.
+func (s *service) ImportPage(ctx context.Context, export *PageExport, categoryID *uint) (*PageResponse, error) {
+	if strings.TrimSpace(export.HTMLContent) == "" {
+		return &PageResponse{Error: "No HTML content provided"}, nil
+	}
+	content, contentErr := s.validateContent(export.HTMLContent)
+	if contentErr != nil {
+		return &PageResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+	}
+	scan, contentErr := s.scanContent(content)
+	if contentErr != nil {
+		return &PageResponse{Error: contentErr.Message, Code: contentErr.Code}, nil
+	}
+
+	slug := export.Slug
+	exists := true
+	if validSlug.MatchString(slug) {
+		var err error
+		if exists, err = s.repo.Exists(ctx, slug); err != nil {
+			logging.FromContext(ctx).Error("checking slug failed", "error", err)
+			return &PageResponse{Error: "Error checking slug"}, err
+		}
+	}
+	if exists {
+		var err error
+		if slug, err = s.GenerateUniqueSlug(ctx); err != nil {
+			logging.FromContext(ctx).Error("generating unique slug failed", "error", err)
+			return &PageResponse{Error: "Error generating unique slug"}, err
+		}
+	}
+
+	title := export.Title
+	if title == "" {
+		title = s.ExtractTitle(content)
+	}
+
+	// Users are not exported, so a page whose owner is missing here is imported without one
+	var ownerID *uint
+	if export.OwnerName != nil && *export.OwnerName != "" {
+		id, err := s.repo.GetUserIDByName(ctx, *export.OwnerName)
+		switch {
+		case err == nil:
+			ownerID = &id
+		case err != gorm.ErrRecordNotFound:
+			logging.FromContext(ctx).Error("looking up owner failed", "error", err)
+			return &PageResponse{Error: "Error looking up owner"}, err
+		}
+	}
+
+	// Archives may carry any offset, but expiry times are stored in UTC like those of new pages
+	var expiresAt *time.Time
+	if export.ExpiresAt != nil {
+		utc := export.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	page := &Page{
+		Slug:          slug,
+		HTMLContent:   content,
+		Title:         title,
+		CategoryID:    categoryID,
+		EditTokenHash: export.EditTokenHash,
+		PasswordHash:  export.PasswordHash,
+		ExpiresAt:     expiresAt,
+		Live:          export.Live,
+		OwnerID:       ownerID,
+		CreatedAt:     export.CreatedAt,
+		UpdatedAt:     export.UpdatedAt,
+	}
+	if scan != nil {
+		page.ScanScore = scan.Score
+		if scan.Verdict == scanner.Quarantine {
+			now := time.Now().UTC()
+			page.QuarantinedAt = &now
+		}
+	}
+
+	err := s.repo.WithTransaction(ctx, func(repo Repository) error {
+		if err := repo.Create(ctx, page); err != nil {
+			return err
+		}
+		return repo.AddTags(ctx, page.ID, normalizeTags(export.Tags))
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("saving content failed", "error", err)
+		return &PageResponse{Error: "Error saving content"}, err
+	}
+	s.reportScan(ctx, page.ID, scan)
+
+	return &PageResponse{URL: "/shared/" + slug, Slug: slug, Quarantined: page.QuarantinedAt != nil}, nil
+}
+
+// PurgeDeletedPages permanently deletes pages soft-deleted before a time
+func (s *service) PurgeDeletedPages(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.Purge(ctx, before.UTC())
+}
+
+// DeleteExpiredPages moves pages whose expiry has passed to the trash
+func (s *service) DeleteExpiredPages(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, time.Now().UTC())
+}
+
+// GetStats returns aggregate statistics about stored pages
+func (s *service) GetStats(ctx context.Context) (*PageStats, error) {
+	return s.repo.Stats(ctx)
+}
+
 // GenerateUniqueSlug generates a unique slug for a new page
 func (s *service) GenerateUniqueSlug(ctx context.Context) (string, error) {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const length = 8
 	const maxAttempts = 10
+	length := s.config.SlugLength
 
 	rand.Seed(time.Now().UnixNano())
 
@@ -142,6 +1208,7 @@ func (s *service) GenerateUniqueSlug(ctx context.Context) (string, error) {
 		if !exists {
 			return slugStr, nil
 		}
+		metrics.SlugCollisions.Inc()
 	}
 
 	return "", fmt.Errorf("failed to generate unique slug after %d attempts", maxAttempts)
@@ -172,3 +1239,18 @@ func (s *service) ExtractTitle(htmlContent string) string {
 	// Default title
 	return "Shared HTML Page"
 }
+
+// generateEditToken creates a random token that authorizes changes to a page
+func generateEditToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := crand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashEditToken returns the hash under which an edit token is stored
+func hashEditToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
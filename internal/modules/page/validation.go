@@ -0,0 +1,164 @@
+package page
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Codes identifying why content failed validation
+const (
+	ContentTooLarge        = "too_large"
+	ContentInvalidEncoding = "invalid_encoding"
+	ContentBinary          = "binary_content"
+	ContentUnsupportedType = "unsupported_type"
+	ContentBlocked         = "blocked_content"
+)
+
+// sniffedTypes are the sniffed MIME types accepted as page content. Plain text
+// covers HTML fragments that do not start with a recognisable tag, and XML
+// covers XHTML documents that start with an XML declaration.
+var sniffedTypes = map[string]bool{
+	"text/html":  true,
+	"text/plain": true,
+	"text/xml":   true,
+}
+
+// metaCharset matches a character set declared by <meta charset> or <meta http-equiv="Content-Type">
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_.:-]+)`)
+
+// metaPrescanBytes is how far into a document a charset declaration is looked for, as browsers do
+const metaPrescanBytes = 1024
+
+// Byte order marks of UTF-16 content, which is not binary despite its NUL bytes
+var (
+	utf16BigEndianBOM    = []byte{0xfe, 0xff}
+	utf16LittleEndianBOM = []byte{0xff, 0xfe}
+)
+
+// maxControlRatio is the share of control characters above which content is treated as binary
+const maxControlRatio = 0.01
+
+// ContentError explains why page content was rejected
+type ContentError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *ContentError) Error() string {
+	return e.Message
+}
+
+// contentCheck inspects content, returning it unchanged or normalised, or an error when it is unacceptable
+type contentCheck func(content []byte) ([]byte, *ContentError)
+
+// validateContent runs page content through the validation pipeline: size,
+// binary detection, character encoding and MIME sniffing. Content in another
+// declared encoding is returned converted to UTF-8.
+func (s *service) validateContent(content string) (string, *ContentError) {
+	checks := []contentCheck{
+		s.checkSize,
+		checkBinary,
+		checkEncoding,
+		checkType,
+	}
+
+	data := []byte(content)
+	for _, check := range checks {
+		var err *ContentError
+		if data, err = check(data); err != nil {
+			return "", err
+		}
+	}
+	return string(data), nil
+}
+
+// checkSize rejects content over the configured limit
+func (s *service) checkSize(content []byte) ([]byte, *ContentError) {
+	if s.config.MaxContentBytes > 0 && int64(len(content)) > s.config.MaxContentBytes {
+		return nil, &ContentError{
+			Code:    ContentTooLarge,
+			Message: fmt.Sprintf("Content exceeds the maximum size of %d bytes", s.config.MaxContentBytes),
+		}
+	}
+	return content, nil
+}
+
+// checkEncoding accepts UTF-8 and converts content in an encoding announced by a
+// byte order mark or a <meta charset> declaration. Content that is not UTF-8 and
+// does not say what it is would be served garbled, so it is rejected.
+func checkEncoding(content []byte) ([]byte, *ContentError) {
+	if utf8.Valid(content) {
+		return bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), nil
+	}
+
+	// A byte order mark is conclusive; otherwise look for a declaration in the document
+	encoding, name, certain := charset.DetermineEncoding(content, "")
+	if !certain {
+		match := metaCharset.FindSubmatch(content[:min(len(content), metaPrescanBytes)])
+		if match == nil {
+			return nil, &ContentError{
+				Code:    ContentInvalidEncoding,
+				Message: "Content is not valid UTF-8 and does not declare its character set",
+			}
+		}
+		if encoding, name = charset.Lookup(string(match[1])); encoding == nil {
+			return nil, &ContentError{
+				Code:    ContentInvalidEncoding,
+				Message: fmt.Sprintf("Content declares an unknown character set %q", match[1]),
+			}
+		}
+	}
+
+	converted, err := encoding.NewDecoder().Bytes(content)
+	if err != nil || !utf8.Valid(converted) {
+		return nil, &ContentError{
+			Code:    ContentInvalidEncoding,
+			Message: fmt.Sprintf("Content could not be decoded as %s", name),
+		}
+	}
+	return bytes.TrimPrefix(converted, []byte("\xef\xbb\xbf")), nil
+}
+
+// checkBinary rejects content containing NUL bytes or more than a trace of
+// control characters. UTF-16 content is left for checkEncoding to convert.
+func checkBinary(content []byte) ([]byte, *ContentError) {
+	if bytes.HasPrefix(content, utf16BigEndianBOM) || bytes.HasPrefix(content, utf16LittleEndianBOM) {
+		return content, nil
+	}
+
+	binary := &ContentError{Code: ContentBinary, Message: "Content looks like a binary file, not HTML"}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil, binary
+	}
+
+	control := 0
+	for _, b := range content {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			control++
+		}
+	}
+	if float64(control) > float64(len(content))*maxControlRatio {
+		return nil, binary
+	}
+	return content, nil
+}
+
+// checkType sniffs the content rather than trusting a file name or header, and
+// rejects anything that is not HTML or text
+func checkType(content []byte) ([]byte, *ContentError) {
+	sniffed, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !sniffedTypes[sniffed] {
+		return nil, &ContentError{
+			Code:    ContentUnsupportedType,
+			Message: fmt.Sprintf("Content is %s, not HTML", sniffed),
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/views/pages"
+)
+
+// adminUserNotices confirms moderation actions, keyed by the done query parameter
+var adminUserNotices = map[string]string{
+	"banned":   "User banned.",
+	"unbanned": "Ban lifted.",
+}
+
+// AdminUsers handles the moderation listing of all users
+func (c *Controller) AdminUsers(ctx *gin.Context) {
+	c.renderAdminUsers(ctx, http.StatusOK, "")
+}
+
+// AdminBan handles banning a user
+func (c *Controller) AdminBan(ctx *gin.Context) {
+	id, ok := adminUserID(ctx)
+	if !ok {
+		return
+	}
+
+	identity := auth.FromContext(ctx.Request.Context())
+	if err := c.service.BanUser(ctx.Request.Context(), identity.UserID, id); err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			ctx.String(http.StatusNotFound, "User not found")
+		case ErrCannotBan:
+			c.renderAdminUsers(ctx, http.StatusConflict, "You cannot ban yourself or another administrator")
+		default:
+			logging.FromContext(ctx.Request.Context()).Error("banning user failed", "error", err)
+			ctx.String(http.StatusInternalServerError, "Error banning user")
+		}
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/users?done=banned"))
+}
+
+// AdminUnban handles lifting a user's ban
+func (c *Controller) AdminUnban(ctx *gin.Context) {
+	id, ok := adminUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.service.UnbanUser(ctx.Request.Context(), id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.String(http.StatusNotFound, "User not found")
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("lifting ban failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error lifting ban")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/admin/users?done=unbanned"))
+}
+
+// renderAdminUsers renders the user listing with an optional error message
+func (c *Controller) renderAdminUsers(ctx *gin.Context, status int, message string) {
+	query := ctx.Query("q")
+	page, pageSize := api.ParsePagination(ctx)
+	users, total, err := c.service.ListUsers(ctx.Request.Context(), query, page, pageSize)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing users failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading users")
+		return
+	}
+
+	data := &pages.AdminUsersData{
+		Users:      make([]pages.AdminUserData, len(users)),
+		Query:      query,
+		Page:       page,
+		TotalPages: api.NewPagination(page, pageSize, total).TotalPages,
+		Total:      total,
+		Notice:     adminUserNotices[ctx.Query("done")],
+		Error:      message,
+	}
+	for i, u := range users {
+		data.Users[i] = pages.AdminUserData{
+			ID:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			Role:      u.Role,
+			BannedAt:  u.BannedAt,
+			PageCount: u.PageCount,
+			CreatedAt: u.CreatedAt,
+		}
+	}
+
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.AdminUsers(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// adminUserID parses the user ID route parameter, writing a 400 response when it is invalid
+func adminUserID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid user ID")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,443 @@
+package user
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/views/pages"
+)
+
+// SessionCookie is the cookie carrying the session token
+const SessionCookie = "sharer_session"
+
+// Controller handles HTTP requests for accounts and sessions
+type Controller struct {
+	service Service
+}
+
+// NewController creates a new user controller
+func NewController(service Service) *Controller {
+	return &Controller{service: service}
+}
+
+// Sessions returns middleware that resolves the session cookie and records the
+// signed-in user in the request context. Requests without a valid session
+// continue anonymously.
+func (c *Controller) Sessions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie(SessionCookie)
+		if err != nil || token == "" {
+			ctx.Next()
+			return
+		}
+
+		user, err := c.service.SessionUser(ctx.Request.Context(), token)
+		switch {
+		case err == nil:
+			identity := &auth.Identity{UserID: user.ID, Username: user.Username, Role: user.Role}
+			ctx.Request = ctx.Request.WithContext(auth.WithIdentity(ctx.Request.Context(), identity))
+		case err == gorm.ErrRecordNotFound:
+			c.clearSessionCookie(ctx)
+		default:
+			logging.FromContext(ctx.Request.Context()).Error("resolving session failed", "error", err)
+		}
+
+		ctx.Next()
+	}
+}
+
+// APITokens returns middleware that authenticates API requests with a bearer
+// token. API requests are never authenticated by the session cookie, so other
+// sites cannot make API calls on behalf of a signed-in browser. Requests without
+// a token continue anonymously; an unknown or revoked token is refused with 401
+// and a token lacking the scope for the request method with 403.
+func (c *Controller) APITokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !strings.HasPrefix(ctx.FullPath(), "/api/") {
+			ctx.Next()
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(auth.WithIdentity(ctx.Request.Context(), nil))
+
+		header := ctx.GetHeader("Authorization")
+		if header == "" {
+			ctx.Next()
+			return
+		}
+
+		scheme, plain, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(plain) == "" {
+			abortUnauthorized(ctx, "Authorization must be a bearer token")
+			return
+		}
+
+		token, user, err := c.service.TokenUser(ctx.Request.Context(), strings.TrimSpace(plain))
+		if err != nil {
+			if err != gorm.ErrRecordNotFound {
+				logging.FromContext(ctx.Request.Context()).Error("resolving API token failed", "error", err)
+				api.AbortWithError(ctx, http.StatusInternalServerError, api.CodeInternal, "Error checking token")
+				return
+			}
+			abortUnauthorized(ctx, "Token is invalid or has been revoked")
+			return
+		}
+
+		identity := &auth.Identity{
+			UserID:   user.ID,
+			Username: user.Username,
+			Role:     user.Role,
+			TokenID:  token.ID,
+			Scopes:   token.Scopes,
+		}
+		scope := auth.ScopeWrite
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			scope = auth.ScopeRead
+		}
+		if !identity.Allows(scope) {
+			api.AbortWithError(ctx, http.StatusForbidden, api.CodeForbidden, "Token lacks the "+scope+" scope")
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(auth.WithIdentity(ctx.Request.Context(), identity))
+		ctx.Next()
+	}
+}
+
+// RequireSignIn returns middleware that only lets signed-in users through: by
+// session in the browser and by API token on /api. Anonymous visitors are sent
+// to sign in, and API clients get a JSON error.
+func (c *Controller) RequireSignIn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if auth.FromContext(ctx.Request.Context()) != nil {
+			ctx.Next()
+			return
+		}
+
+		if strings.HasPrefix(ctx.FullPath(), "/api/") {
+			abortUnauthorized(ctx, "An API token is required")
+			return
+		}
+
+		next := "/"
+		if ctx.Request.Method == http.MethodGet {
+			next = ctx.Request.URL.RequestURI()
+		}
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/signin?next="+url.QueryEscape(next)))
+		ctx.Abort()
+	}
+}
+
+// RequireAdmin returns middleware that only lets administrators through.
+// Anonymous visitors are sent to sign in, and API clients get a JSON error.
+func (c *Controller) RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity := auth.FromContext(ctx.Request.Context())
+		if identity != nil && identity.IsAdmin() {
+			ctx.Next()
+			return
+		}
+
+		if strings.HasPrefix(ctx.FullPath(), "/api/") {
+			if identity == nil {
+				abortUnauthorized(ctx, "An API token with the admin scope is required")
+			} else {
+				api.AbortWithError(ctx, http.StatusForbidden, api.CodeForbidden, "Requires an administrator's token with the admin scope")
+			}
+			return
+		}
+
+		if identity == nil {
+			next := "/admin"
+			if ctx.Request.Method == http.MethodGet {
+				next = ctx.Request.URL.RequestURI()
+			}
+			ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/signin?next="+url.QueryEscape(next)))
+			ctx.Abort()
+			return
+		}
+		ctx.Abort()
+		ctx.String(http.StatusForbidden, "Administrators only")
+	}
+}
+
+// abortUnauthorized refuses an API request whose credentials are not valid
+func abortUnauthorized(ctx *gin.Context, message string) {
+	ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	api.AbortWithError(ctx, http.StatusUnauthorized, api.CodeUnauthorized, message)
+}
+
+// SignUpForm handles the sign-up page display
+func (c *Controller) SignUpForm(ctx *gin.Context) {
+	c.renderSignUp(ctx, http.StatusOK, &pages.SignUpData{})
+}
+
+// SignUp handles sign-up form submissions, signing the new user in
+func (c *Controller) SignUp(ctx *gin.Context) {
+	var req UserCreate
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.renderSignUp(ctx, http.StatusBadRequest, &pages.SignUpData{
+			Username: req.Username,
+			Email:    req.Email,
+			Error:    "Username, email and password are required",
+		})
+		return
+	}
+
+	response, err := c.service.Register(ctx.Request.Context(), &req)
+	if err != nil {
+		c.renderSignUp(ctx, http.StatusInternalServerError, &pages.SignUpData{
+			Username: req.Username,
+			Email:    req.Email,
+			Error:    "Something went wrong, please try again",
+		})
+		return
+	}
+
+	if response.Error != "" {
+		c.renderSignUp(ctx, http.StatusUnprocessableEntity, &pages.SignUpData{
+			Username: req.Username,
+			Email:    req.Email,
+			Error:    response.Error,
+		})
+		return
+	}
+
+	if !c.startSession(ctx, response.User.ID) {
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/signin"))
+		return
+	}
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/"))
+}
+
+// SignInForm handles the sign-in page display
+func (c *Controller) SignInForm(ctx *gin.Context) {
+	c.renderSignIn(ctx, http.StatusOK, &pages.SignInData{Next: localPath(ctx.Query("next"))})
+}
+
+// SignIn handles sign-in form submissions
+func (c *Controller) SignIn(ctx *gin.Context) {
+	next := localPath(ctx.PostForm("next"))
+
+	var req UserLogin
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.renderSignIn(ctx, http.StatusBadRequest, &pages.SignInData{
+			Login: req.Login,
+			Next:  next,
+			Error: "Enter your username or email and password",
+		})
+		return
+	}
+
+	user, err := c.service.Authenticate(ctx.Request.Context(), &req)
+	if err != nil {
+		status, message := http.StatusUnauthorized, "Incorrect username, email or password"
+		if err == ErrBanned {
+			status, message = http.StatusForbidden, "This account has been suspended"
+		} else if err != ErrInvalidCredentials {
+			logging.FromContext(ctx.Request.Context()).Error("authenticating user failed", "error", err)
+			status, message = http.StatusInternalServerError, "Something went wrong, please try again"
+		}
+		c.renderSignIn(ctx, status, &pages.SignInData{Login: req.Login, Next: next, Error: message})
+		return
+	}
+
+	if !c.startSession(ctx, user.ID) {
+		c.renderSignIn(ctx, http.StatusInternalServerError, &pages.SignInData{
+			Login: req.Login,
+			Next:  next,
+			Error: "Something went wrong, please try again",
+		})
+		return
+	}
+
+	if next == "" {
+		next = "/"
+	}
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), next))
+}
+
+// SignOut handles signing out, ending the session server-side
+func (c *Controller) SignOut(ctx *gin.Context) {
+	if token, err := ctx.Cookie(SessionCookie); err == nil && token != "" {
+		if err := c.service.EndSession(ctx.Request.Context(), token); err != nil {
+			logging.FromContext(ctx.Request.Context()).Error("ending session failed", "error", err)
+		}
+	}
+	c.clearSessionCookie(ctx)
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/"))
+}
+
+// Tokens handles the API token management page
+func (c *Controller) Tokens(ctx *gin.Context) {
+	identity, ok := c.signedIn(ctx, "/account/tokens")
+	if !ok {
+		return
+	}
+	c.renderTokens(ctx, http.StatusOK, identity.UserID, &pages.TokensData{})
+}
+
+// CreateToken handles API token creation form submissions, showing the new token once
+func (c *Controller) CreateToken(ctx *gin.Context) {
+	identity, ok := c.signedIn(ctx, "/account/tokens")
+	if !ok {
+		return
+	}
+
+	var req TokenCreate
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.renderTokens(ctx, http.StatusBadRequest, identity.UserID, &pages.TokensData{
+			Scopes: req.Scopes,
+			Error:  "Give the token a name",
+		})
+		return
+	}
+
+	response, err := c.service.CreateToken(ctx.Request.Context(), identity.UserID, &req)
+	if err != nil {
+		c.renderTokens(ctx, http.StatusInternalServerError, identity.UserID, &pages.TokensData{
+			Name:   req.Name,
+			Scopes: req.Scopes,
+			Error:  "Something went wrong, please try again",
+		})
+		return
+	}
+
+	if response.Error != "" {
+		c.renderTokens(ctx, http.StatusUnprocessableEntity, identity.UserID, &pages.TokensData{
+			Name:   req.Name,
+			Scopes: req.Scopes,
+			Error:  response.Error,
+		})
+		return
+	}
+
+	c.renderTokens(ctx, http.StatusCreated, identity.UserID, &pages.TokensData{NewToken: response.Token})
+}
+
+// RevokeToken handles revoking an API token
+func (c *Controller) RevokeToken(ctx *gin.Context) {
+	identity, ok := c.signedIn(ctx, "/account/tokens")
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := c.service.RevokeToken(ctx.Request.Context(), identity.UserID, uint(id)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.String(http.StatusNotFound, "Token not found")
+			return
+		}
+		logging.FromContext(ctx.Request.Context()).Error("revoking token failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error revoking token")
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/account/tokens"))
+}
+
+// signedIn returns the signed-in user, or redirects to the sign-in page and
+// returns false, coming back to next afterwards
+func (c *Controller) signedIn(ctx *gin.Context, next string) (*auth.Identity, bool) {
+	identity := auth.FromContext(ctx.Request.Context())
+	if identity == nil {
+		ctx.Redirect(http.StatusSeeOther, links.Path(ctx.Request.Context(), "/signin?next="+url.QueryEscape(next)))
+		return nil, false
+	}
+	return identity, true
+}
+
+// startSession starts a session for a user and sets its cookie, reporting whether it succeeded
+func (c *Controller) startSession(ctx *gin.Context, userID uint) bool {
+	session, err := c.service.StartSession(ctx.Request.Context(), &SessionCreate{
+		UserID:    userID,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("starting session failed", "error", err)
+		return false
+	}
+
+	c.setSessionCookie(ctx, session.Token, int(time.Until(session.ExpiresAt).Seconds()))
+	return true
+}
+
+// setSessionCookie writes the session cookie, scoped to the public path of the application.
+// A negative maxAge deletes it.
+func (c *Controller) setSessionCookie(ctx *gin.Context, token string, maxAge int) {
+	public := links.FromContext(ctx.Request.Context())
+	path := public.Prefix + "/"
+	secure := strings.HasPrefix(public.Origin, "https://")
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(SessionCookie, token, maxAge, path, "", secure, true)
+}
+
+// clearSessionCookie deletes the session cookie
+func (c *Controller) clearSessionCookie(ctx *gin.Context) {
+	c.setSessionCookie(ctx, "", -1)
+}
+
+// renderSignUp renders the sign-up page
+func (c *Controller) renderSignUp(ctx *gin.Context, status int, data *pages.SignUpData) {
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.SignUp(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// renderSignIn renders the sign-in page
+func (c *Controller) renderSignIn(ctx *gin.Context, status int, data *pages.SignInData) {
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.SignIn(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// renderTokens renders the API token page with the user's tokens
+func (c *Controller) renderTokens(ctx *gin.Context, status int, userID uint, data *pages.TokensData) {
+	tokens, err := c.service.ListTokens(ctx.Request.Context(), userID)
+	if err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("listing tokens failed", "error", err)
+		ctx.String(http.StatusInternalServerError, "Error loading tokens")
+		return
+	}
+
+	for _, token := range tokens {
+		data.Tokens = append(data.Tokens, pages.TokenData{
+			ID:         token.ID,
+			Name:       token.Name,
+			Prefix:     token.Prefix,
+			Scopes:     token.Scopes,
+			LastUsedAt: token.LastUsedAt,
+			RevokedAt:  token.RevokedAt,
+			CreatedAt:  token.CreatedAt,
+		})
+	}
+
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html")
+	pages.Tokens(data).Render(ctx.Request.Context(), ctx.Writer)
+}
+
+// localPath returns path if it is a path within the application, or empty, so
+// that redirects after signing in cannot send users to another site
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.ContainsAny(path, "\\\r\n") {
+		return ""
+	}
+	return path
+}
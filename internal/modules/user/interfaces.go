@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for user data access operations
+type Repository interface {
+	// Create creates a new user
+	Create(ctx context.Context, user *User) error
+
+	// GetByID retrieves a user by its ID
+	GetByID(ctx context.Context, id uint) (*User, error)
+
+	// GetByLogin retrieves a user by username or email address
+	GetByLogin(ctx context.Context, login string) (*User, error)
+
+	// List retrieves a paginated list of users whose username or email contains query, with their page counts
+	List(ctx context.Context, query string, offset, limit int) ([]*UserList, error)
+
+	// Count returns the number of users whose username or email contains query
+	Count(ctx context.Context, query string) (int64, error)
+
+	// SetBanned bans a user at a time, or lifts the ban when bannedAt is nil
+	SetBanned(ctx context.Context, id uint, bannedAt *time.Time) error
+
+	// SetRole changes a user's role
+	SetRole(ctx context.Context, id uint, role string) error
+
+	// UsernameExists checks if a username is already taken
+	UsernameExists(ctx context.Context, username string) (bool, error)
+
+	// EmailExists checks if an email address is already registered
+	EmailExists(ctx context.Context, email string) (bool, error)
+
+	// CreateSession stores a new session
+	CreateSession(ctx context.Context, session *Session) error
+
+	// GetSession retrieves an unexpired session by token hash, along with its user
+	GetSession(ctx context.Context, tokenHash string, now time.Time) (*Session, error)
+
+	// DeleteSession removes a session by token hash
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	// DeleteExpiredSessions removes sessions that expired before now and returns how many were removed
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+
+	// CreateToken stores a new API token
+	CreateToken(ctx context.Context, token *APIToken) error
+
+	// ListTokens retrieves all API tokens of a user, newest first
+	ListTokens(ctx context.Context, userID uint) ([]APIToken, error)
+
+	// GetToken retrieves an unrevoked API token by hash, along with its user
+	GetToken(ctx context.Context, tokenHash string) (*APIToken, error)
+
+	// RevokeToken marks a user's API token as revoked, returning gorm.ErrRecordNotFound when the user has no such active token
+	RevokeToken(ctx context.Context, userID, id uint, now time.Time) error
+
+	// TouchToken records when an API token was last used
+	TouchToken(ctx context.Context, id uint, now time.Time) error
+}
+
+// Service defines the interface for user business logic operations
+type Service interface {
+	// Register creates a new user account
+	Register(ctx context.Context, req *UserCreate) (*UserResponse, error)
+
+	// Authenticate checks a username or email and password, returning ErrInvalidCredentials when they do not match
+	Authenticate(ctx context.Context, req *UserLogin) (*UserDetail, error)
+
+	// GetUserByID retrieves a user by its ID
+	GetUserByID(ctx context.Context, id uint) (*UserDetail, error)
+
+	// ListUsers retrieves a paginated list of users, optionally searching by username or email
+	ListUsers(ctx context.Context, query string, page, pageSize int) ([]*UserList, int64, error)
+
+	// BanUser bans a user, signing them out everywhere until the ban is lifted
+	BanUser(ctx context.Context, actorID, id uint) error
+
+	// UnbanUser lifts a user's ban
+	UnbanUser(ctx context.Context, id uint) error
+
+	// SetRole changes the role of the user with a username or email address
+	SetRole(ctx context.Context, login, role string) (*UserDetail, error)
+
+	// StartSession starts a session for a user and returns its token
+	StartSession(ctx context.Context, req *SessionCreate) (*SessionResponse, error)
+
+	// SessionUser returns the user a session token belongs to, or gorm.ErrRecordNotFound when it is unknown or expired
+	SessionUser(ctx context.Context, token string) (*UserDetail, error)
+
+	// EndSession signs a session out
+	EndSession(ctx context.Context, token string) error
+
+	// DeleteExpiredSessions removes expired sessions and returns how many were removed
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+
+	// CreateToken creates an API token for a user
+	CreateToken(ctx context.Context, userID uint, req *TokenCreate) (*TokenResponse, error)
+
+	// ListTokens retrieves all API tokens of a user
+	ListTokens(ctx context.Context, userID uint) ([]TokenDetail, error)
+
+	// RevokeToken revokes one of a user's API tokens
+	RevokeToken(ctx context.Context, userID, id uint) error
+
+	// TokenUser returns an active API token and its owner, or gorm.ErrRecordNotFound when it is unknown or revoked
+	TokenUser(ctx context.Context, token string) (*TokenDetail, *UserDetail, error)
+}
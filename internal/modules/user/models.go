@@ -2,25 +2,64 @@ package user
 
 import (
 	"time"
+
 	"gorm.io/gorm"
 )
 
-// User represents the database table for users (example model)
+// User represents the database table for users
 type User struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
 	Username  string         `gorm:"uniqueIndex;not null" json:"username"`
 	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
 	Password  string         `gorm:"not null" json:"-"`
+	Role      string         `gorm:"size:16;not null;default:member" json:"role"`
+	BannedAt  *time.Time     `json:"banned_at,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Session represents a signed-in browser session stored server-side.
+// Only a hash of the session token is kept, so a leaked database cannot be used to sign in.
+type Session struct {
+	ID        uint      `gorm:"primarykey"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// APIToken represents a personal access token for the API.
+// Only a hash of the token is kept; Prefix is stored so users can tell their tokens apart.
+type APIToken struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"index;not null"`
+	User      User   `gorm:"constraint:OnDelete:CASCADE"`
+	Name      string `gorm:"size:64;not null"`
+	Prefix    string `gorm:"size:16;not null"`
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	// Scopes is the space separated list of granted scopes
+	Scopes     string `gorm:"not null"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
 // UserCreate represents the data needed to create a new user
 type UserCreate struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" form:"username" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
+}
+
+// UserLogin represents the credentials submitted to sign in
+type UserLogin struct {
+	// Login is the username or email address
+	Login    string `json:"login" form:"login" binding:"required"`
+	Password string `json:"password" form:"password" binding:"required"`
 }
 
 // UserUpdate represents the data that can be updated for a user
@@ -32,23 +71,65 @@ type UserUpdate struct {
 
 // UserList represents a simplified user for listing purposes
 type UserList struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	PageCount int64      `json:"page_count"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 // UserDetail represents detailed user information
 type UserDetail struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 // UserResponse represents the API response for user operations
 type UserResponse struct {
 	User  *UserDetail `json:"user,omitempty"`
 	Error string      `json:"error,omitempty"`
-}
\ No newline at end of file
+}
+
+// TokenCreate represents the data needed to create an API token
+type TokenCreate struct {
+	Name   string   `form:"name" binding:"required"`
+	Scopes []string `form:"scopes"`
+}
+
+// TokenDetail represents an API token without its secret
+type TokenDetail struct {
+	ID         uint
+	Name       string
+	Prefix     string
+	Scopes     []string
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// TokenResponse represents the result of creating an API token. The token is only ever returned here.
+type TokenResponse struct {
+	Token  string
+	Detail *TokenDetail
+	Error  string
+}
+
+// SessionCreate describes the client a session is started for
+type SessionCreate struct {
+	UserID    uint
+	IP        string
+	UserAgent string
+}
+
+// SessionResponse represents a newly started session. The token is only ever returned here.
+type SessionResponse struct {
+	Token     string
+	ExpiresAt time.Time
+}
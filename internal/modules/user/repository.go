@@ -0,0 +1,186 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// repository implements the Repository interface using GORM
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new user repository
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// Create creates a new user
+func (r *repository) Create(ctx context.Context, user *User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+// GetByID retrieves a user by its ID
+func (r *repository) GetByID(ctx context.Context, id uint) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByLogin retrieves a user by username or email address. Email addresses
+// are stored lower-cased, so they match regardless of case.
+func (r *repository) GetByLogin(ctx context.Context, login string) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).
+		Where("username = ? OR email = ?", login, strings.ToLower(login)).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List retrieves a paginated list of users whose username or email contains query, with their page counts
+func (r *repository) List(ctx context.Context, query string, offset, limit int) ([]*UserList, error) {
+	var users []*UserList
+	err := r.search(ctx, query).
+		Select("users.id, users.username, users.email, users.role, users.banned_at, users.created_at, " +
+			"(SELECT COUNT(*) FROM shared_content p WHERE p.owner_id = users.id AND p.deleted_at IS NULL) AS page_count").
+		Order("users.created_at DESC, users.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// Count returns the number of users whose username or email contains query
+func (r *repository) Count(ctx context.Context, query string) (int64, error) {
+	var count int64
+	err := r.search(ctx, query).Count(&count).Error
+	return count, err
+}
+
+// search returns a query over users whose username or email contains query
+func (r *repository) search(ctx context.Context, query string) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&User{})
+	if query != "" {
+		like := "%" + query + "%"
+		db = db.Where("users.username LIKE ? OR users.email LIKE ?", like, like)
+	}
+	return db
+}
+
+// SetBanned bans a user at a time, or lifts the ban when bannedAt is nil
+func (r *repository) SetBanned(ctx context.Context, id uint, bannedAt *time.Time) error {
+	return r.updateUser(ctx, id, "banned_at", bannedAt)
+}
+
+// SetRole changes a user's role
+func (r *repository) SetRole(ctx context.Context, id uint, role string) error {
+	return r.updateUser(ctx, id, "role", role)
+}
+
+// updateUser sets one column of a user, returning gorm.ErrRecordNotFound when there is no such user
+func (r *repository) updateUser(ctx context.Context, id uint, column string, value interface{}) error {
+	result := r.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).Update(column, value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UsernameExists checks if a username is already taken, including by deleted users
+func (r *repository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+// EmailExists checks if an email address is already registered, including by deleted users
+func (r *repository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateSession stores a new session
+func (r *repository) CreateSession(ctx context.Context, session *Session) error {
+	return r.db.WithContext(ctx).Omit("User").Create(session).Error
+}
+
+// GetSession retrieves an unexpired session by token hash, along with its user
+func (r *repository) GetSession(ctx context.Context, tokenHash string, now time.Time) (*Session, error) {
+	var session Session
+	err := r.db.WithContext(ctx).
+		Joins("User").
+		Where("sessions.token_hash = ? AND sessions.expires_at > ?", tokenHash, now).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// DeleteSession removes a session by token hash
+func (r *repository) DeleteSession(ctx context.Context, tokenHash string) error {
+	return r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&Session{}).Error
+}
+
+// DeleteExpiredSessions removes sessions that expired before now and returns how many were removed
+func (r *repository) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
+
+// CreateToken stores a new API token
+func (r *repository) CreateToken(ctx context.Context, token *APIToken) error {
+	return r.db.WithContext(ctx).Omit("User").Create(token).Error
+}
+
+// ListTokens retrieves all API tokens of a user, newest first
+func (r *repository) ListTokens(ctx context.Context, userID uint) ([]APIToken, error) {
+	var tokens []APIToken
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// GetToken retrieves an unrevoked API token by hash, along with its user
+func (r *repository) GetToken(ctx context.Context, tokenHash string) (*APIToken, error) {
+	var token APIToken
+	err := r.db.WithContext(ctx).
+		Joins("User").
+		Where("api_tokens.token_hash = ? AND api_tokens.revoked_at IS NULL", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeToken marks a user's API token as revoked
+func (r *repository) RevokeToken(ctx context.Context, userID, id uint, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchToken records when an API token was last used
+func (r *repository) TouchToken(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&APIToken{}).Where("id = ?", id).Update("last_used_at", now).Error
+}
@@ -0,0 +1,414 @@
+package user
+
+import (
+	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"sharer/internal/auth"
+	"sharer/internal/logging"
+)
+
+// ErrInvalidCredentials is returned when a login does not match any user or password
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrBanned is returned when a banned user tries to sign in
+var ErrBanned = errors.New("account is banned")
+
+// ErrCannotBan is returned when banning a user that may not be banned: the acting admin or another admin
+var ErrCannotBan = errors.New("user cannot be banned")
+
+// ErrInvalidRole is returned when setting a role that does not exist
+var ErrInvalidRole = errors.New("invalid role")
+
+// Password length limits; bcrypt ignores everything after 72 bytes
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+// validUsername matches usernames that are safe to show in URLs and pages
+var validUsername = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// TokenPrefix starts every API token, so leaked tokens are easy to recognise
+const TokenPrefix = "shr_"
+
+// tokenTouchInterval limits how often the last-used time of an API token is written
+const tokenTouchInterval = time.Minute
+
+// maxTokenNameLength is the longest name an API token can be given
+const maxTokenNameLength = 64
+
+// dummyHash is compared against when a login is unknown, so that failed
+// sign-ins take as long whether or not the account exists
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("sharer-dummy-password"), bcrypt.DefaultCost)
+
+// Config holds user service settings
+type Config struct {
+	// SessionLifetime is how long a session stays valid after signing in, 30 days when zero
+	SessionLifetime time.Duration
+	// Registration allows new accounts to be created
+	Registration bool
+}
+
+// service implements the Service interface
+type service struct {
+	repo   Repository
+	config Config
+}
+
+// NewService creates a new user service
+func NewService(repo Repository, config Config) Service {
+	if config.SessionLifetime == 0 {
+		config.SessionLifetime = 30 * 24 * time.Hour
+	}
+	return &service{repo: repo, config: config}
+}
+
+// Register creates a new user account
+func (s *service) Register(ctx context.Context, req *UserCreate) (*UserResponse, error) {
+	if !s.config.Registration {
+		return &UserResponse{Error: "Registration is closed"}, nil
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if !validUsername.MatchString(username) {
+		return &UserResponse{Error: "Username must be 3 to 32 letters, digits, dashes or underscores"}, nil
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil || address.Name != "" {
+		return &UserResponse{Error: "Email address is not valid"}, nil
+	}
+	email := strings.ToLower(address.Address)
+
+	if len(req.Password) < minPasswordLength || len(req.Password) > maxPasswordLength {
+		return &UserResponse{Error: fmt.Sprintf("Password must be %d to %d characters", minPasswordLength, maxPasswordLength)}, nil
+	}
+
+	taken, err := s.repo.UsernameExists(ctx, username)
+	if err != nil {
+		logging.FromContext(ctx).Error("checking username failed", "error", err)
+		return &UserResponse{Error: "Error checking username"}, err
+	}
+	if taken {
+		return &UserResponse{Error: "Username is already taken"}, nil
+	}
+
+	registered, err := s.repo.EmailExists(ctx, email)
+	if err != nil {
+		logging.FromContext(ctx).Error("checking email failed", "error", err)
+		return &UserResponse{Error: "Error checking email"}, err
+	}
+	if registered {
+		return &UserResponse{Error: "An account with this email address already exists"}, nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logging.FromContext(ctx).Error("hashing password failed", "error", err)
+		return &UserResponse{Error: "Error hashing password"}, err
+	}
+
+	user := &User{
+		Username: username,
+		Email:    email,
+		Password: string(hash),
+		Role:     auth.RoleMember,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		logging.FromContext(ctx).Error("creating user failed", "error", err)
+		return &UserResponse{Error: "Error creating account"}, err
+	}
+
+	return &UserResponse{User: toDetail(user)}, nil
+}
+
+// Authenticate checks a username or email and password
+func (s *service) Authenticate(ctx context.Context, req *UserLogin) (*UserDetail, error) {
+	user, err := s.repo.GetByLogin(ctx, strings.TrimSpace(req.Login))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.BannedAt != nil {
+		return nil, ErrBanned
+	}
+
+	return toDetail(user), nil
+}
+
+// GetUserByID retrieves a user by its ID
+func (s *service) GetUserByID(ctx context.Context, id uint) (*UserDetail, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDetail(user), nil
+}
+
+// ListUsers retrieves a paginated list of users, optionally searching by username or email
+func (s *service) ListUsers(ctx context.Context, query string, page, pageSize int) ([]*UserList, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	query = strings.TrimSpace(query)
+
+	users, err := s.repo.List(ctx, query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.repo.Count(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// BanUser bans a user. Sessions and API tokens of banned users are refused, so
+// they are signed out everywhere, and lifting the ban restores them.
+func (s *service) BanUser(ctx context.Context, actorID, id uint) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.ID == actorID || user.Role == auth.RoleAdmin {
+		return ErrCannotBan
+	}
+	if user.BannedAt != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return s.repo.SetBanned(ctx, id, &now)
+}
+
+// UnbanUser lifts a user's ban
+func (s *service) UnbanUser(ctx context.Context, id uint) error {
+	return s.repo.SetBanned(ctx, id, nil)
+}
+
+// SetRole changes the role of the user with a username or email address
+func (s *service) SetRole(ctx context.Context, login, role string) (*UserDetail, error) {
+	if role != auth.RoleAdmin && role != auth.RoleMember {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.repo.GetByLogin(ctx, strings.TrimSpace(login))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	return toDetail(user), nil
+}
+
+// StartSession starts a session for a user and returns its token
+func (s *service) StartSession(ctx context.Context, req *SessionCreate) (*SessionResponse, error) {
+	token, err := generateSessionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		TokenHash: hashSessionToken(token),
+		UserID:    req.UserID,
+		ExpiresAt: time.Now().UTC().Add(s.config.SessionLifetime),
+		IP:        req.IP,
+		UserAgent: truncate(req.UserAgent, 255),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &SessionResponse{Token: token, ExpiresAt: session.ExpiresAt}, nil
+}
+
+// SessionUser returns the user a session token belongs to
+func (s *service) SessionUser(ctx context.Context, token string) (*UserDetail, error) {
+	session, err := s.repo.GetSession(ctx, hashSessionToken(token), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	// The user may have been deleted or banned since signing in
+	if session.User.ID == 0 || session.User.BannedAt != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return toDetail(&session.User), nil
+}
+
+// EndSession signs a session out
+func (s *service) EndSession(ctx context.Context, token string) error {
+	return s.repo.DeleteSession(ctx, hashSessionToken(token))
+}
+
+// DeleteExpiredSessions removes expired sessions
+func (s *service) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredSessions(ctx, time.Now().UTC())
+}
+
+// CreateToken creates an API token for a user
+func (s *service) CreateToken(ctx context.Context, userID uint, req *TokenCreate) (*TokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxTokenNameLength {
+		return &TokenResponse{Error: fmt.Sprintf("Token name must be 1 to %d characters", maxTokenNameLength)}, nil
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return &TokenResponse{Error: "Unknown scope " + scope}, nil
+		}
+	}
+	var scopes []string
+	for _, scope := range auth.Scopes {
+		if slices.Contains(req.Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return &TokenResponse{Error: "Choose at least one scope"}, nil
+	}
+
+	secret, err := generateSessionToken()
+	if err != nil {
+		logging.FromContext(ctx).Error("generating token failed", "error", err)
+		return &TokenResponse{Error: "Error generating token"}, err
+	}
+	plain := TokenPrefix + secret
+
+	token := &APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:len(TokenPrefix)+8],
+		TokenHash: hashSessionToken(plain),
+		Scopes:    strings.Join(scopes, " "),
+	}
+	if err := s.repo.CreateToken(ctx, token); err != nil {
+		logging.FromContext(ctx).Error("creating token failed", "error", err)
+		return &TokenResponse{Error: "Error creating token"}, err
+	}
+
+	return &TokenResponse{Token: plain, Detail: toTokenDetail(token)}, nil
+}
+
+// ListTokens retrieves all API tokens of a user
+func (s *service) ListTokens(ctx context.Context, userID uint) ([]TokenDetail, error) {
+	tokens, err := s.repo.ListTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]TokenDetail, 0, len(tokens))
+	for i := range tokens {
+		details = append(details, *toTokenDetail(&tokens[i]))
+	}
+	return details, nil
+}
+
+// RevokeToken revokes one of a user's API tokens
+func (s *service) RevokeToken(ctx context.Context, userID, id uint) error {
+	return s.repo.RevokeToken(ctx, userID, id, time.Now().UTC())
+}
+
+// TokenUser returns an active API token and its owner, recording that the token was used
+func (s *service) TokenUser(ctx context.Context, plain string) (*TokenDetail, *UserDetail, error) {
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	token, err := s.repo.GetToken(ctx, hashSessionToken(plain))
+	if err != nil {
+		return nil, nil, err
+	}
+	// The user may have been deleted or banned since the token was created
+	if token.User.ID == 0 || token.User.BannedAt != nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	// Writing on every request would turn reads into writes, so the last-used time is coarse
+	now := time.Now().UTC()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= tokenTouchInterval {
+		if err := s.repo.TouchToken(ctx, token.ID, now); err != nil {
+			logging.FromContext(ctx).Error("recording token use failed", "error", err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+
+	return toTokenDetail(token), toDetail(&token.User), nil
+}
+
+// toTokenDetail converts an API token to its public representation
+func toTokenDetail(token *APIToken) *TokenDetail {
+	return &TokenDetail{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     strings.Fields(token.Scopes),
+		LastUsedAt: token.LastUsedAt,
+		RevokedAt:  token.RevokedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// toDetail converts a user to its public representation
+func toDetail(user *User) *UserDetail {
+	return &UserDetail{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		BannedAt:  user.BannedAt,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// generateSessionToken returns a random session token, also used as the secret part of API tokens
+func generateSessionToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := crand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashSessionToken returns the hash under which a session or API token is stored
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharer/internal/links"
+	"sharer/internal/logging"
+)
+
+// spec is the OpenAPI 3 document describing the JSON API
+//
+//go:embed openapi.json
+var spec []byte
+
+// Spec returns the OpenAPI 3 document describing the JSON API
+func Spec() []byte {
+	return spec
+}
+
+// server is an entry of the document's servers list
+type server struct {
+	URL string `json:"url"`
+}
+
+// Handler serves the OpenAPI document, with the public location of the
+// application as its server so that clients generated from it work behind a
+// reverse proxy mounted under a sub-path
+func Handler(ctx *gin.Context) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		logging.FromContext(ctx.Request.Context()).Error("decoding OpenAPI document failed", "error", err)
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// Paths in the document start with a slash, so the server URL has none at the end
+	url := links.Absolute(ctx.Request.Context(), "")
+	if url == "" {
+		url = "/"
+	}
+	servers, _ := json.Marshal([]server{{URL: url}})
+	doc["servers"] = servers
+
+	ctx.JSON(http.StatusOK, doc)
+}
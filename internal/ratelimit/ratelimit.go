@@ -0,0 +1,147 @@
+// Package ratelimit throttles clients with token buckets held in memory.
+//
+// Each client gets a bucket that holds up to Burst tokens and refills at a
+// steady rate; a request spends one token and is refused when none are left.
+// A bucket that has refilled completely is indistinguishable from a new one,
+// so Sweep drops such buckets to keep memory bounded by the number of clients
+// active recently.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharer/internal/api"
+	"sharer/internal/auth"
+	"sharer/internal/metrics"
+)
+
+// Limit is a sustained request rate with an allowance for bursts
+type Limit struct {
+	// PerMinute is the sustained number of requests allowed per minute
+	PerMinute float64
+	// Burst is the number of requests allowed at once after a quiet period
+	Burst int
+}
+
+// bucket holds the tokens a client has left
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter rate limits requests per client key
+type Limiter struct {
+	name  string
+	rate  float64 // tokens per second
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New creates a limiter, or returns nil when the limit disables rate limiting.
+// The name identifies the limiter in logs and metrics.
+func New(name string, limit Limit) *Limiter {
+	if limit.PerMinute <= 0 || limit.Burst <= 0 {
+		return nil
+	}
+	return &Limiter{
+		name:    name,
+		rate:    limit.PerMinute / 60,
+		burst:   float64(limit.Burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow spends a token for key at now. When none is left it reports how long
+// until one will be.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// Sweep drops the buckets that have refilled completely by now and returns how many it dropped
+func (l *Limiter) Sweep(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	dropped := 0
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Len returns the number of clients currently tracked
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// Middleware refuses requests over the limit with 429 Too Many Requests and a
+// Retry-After header. A nil limiter lets every request through.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	if l == nil {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		allowed, wait := l.Allow(ClientKey(ctx), time.Now())
+		if allowed {
+			ctx.Next()
+			return
+		}
+
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		metrics.RateLimited.WithLabelValues(l.name).Inc()
+
+		message := "Too many requests, retry in " + strconv.Itoa(retryAfter) + " seconds"
+		if strings.HasPrefix(ctx.FullPath(), "/api/") {
+			api.AbortWithError(ctx, http.StatusTooManyRequests, api.CodeRateLimited, message)
+			return
+		}
+		ctx.Abort()
+		ctx.String(http.StatusTooManyRequests, message)
+	}
+}
+
+// ClientKey identifies the client of a request: by API token when the request
+// was authenticated with one, so that clients behind a shared address are
+// limited separately, and by IP address otherwise. Only tokens that have been
+// verified count, so made-up tokens cannot be used to get fresh buckets.
+func ClientKey(ctx *gin.Context) string {
+	if identity := auth.FromContext(ctx.Request.Context()); identity != nil && identity.TokenID != 0 {
+		return "token:" + strconv.FormatUint(uint64(identity.TokenID), 10)
+	}
+	return "ip:" + ctx.ClientIP()
+}
@@ -0,0 +1,190 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharer/internal/auth"
+)
+
+func TestNewDisabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+	}{
+		{"zero rate", Limit{PerMinute: 0, Burst: 10}},
+		{"zero burst", Limit{PerMinute: 60, Burst: 0}},
+		{"negative rate", Limit{PerMinute: -1, Burst: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if l := New("test", tt.limit); l != nil {
+				t.Errorf("New(%+v) = %v, want nil", tt.limit, l)
+			}
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type step struct {
+		key     string
+		after   time.Duration
+		allowed bool
+		wait    time.Duration
+	}
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "burst then refused",
+			limit: Limit{PerMinute: 60, Burst: 2},
+			steps: []step{
+				{"a", 0, true, 0},
+				{"a", 0, true, 0},
+				{"a", 0, false, time.Second},
+			},
+		},
+		{
+			name:  "refills at the sustained rate",
+			limit: Limit{PerMinute: 60, Burst: 1},
+			steps: []step{
+				{"a", 0, true, 0},
+				{"a", 500 * time.Millisecond, false, 500 * time.Millisecond},
+				{"a", time.Second, true, 0},
+			},
+		},
+		{
+			name:  "refill stops at the burst",
+			limit: Limit{PerMinute: 60, Burst: 2},
+			steps: []step{
+				{"a", 0, true, 0},
+				{"a", time.Hour, true, 0},
+				{"a", time.Hour, true, 0},
+				{"a", time.Hour, false, time.Second},
+			},
+		},
+		{
+			name:  "clients have separate buckets",
+			limit: Limit{PerMinute: 60, Burst: 1},
+			steps: []step{
+				{"a", 0, true, 0},
+				{"a", 0, false, time.Second},
+				{"b", 0, true, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New("test", tt.limit)
+			for i, s := range tt.steps {
+				allowed, wait := l.Allow(s.key, start.Add(s.after))
+				if allowed != s.allowed || wait != s.wait {
+					t.Errorf("step %d: Allow(%q, +%s) = %v, %s, want %v, %s", i, s.key, s.after, allowed, wait, s.allowed, s.wait)
+				}
+			}
+		})
+	}
+}
+
+func TestSweep(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New("test", Limit{PerMinute: 60, Burst: 2})
+
+	l.Allow("idle", start)
+	l.Allow("busy", start.Add(time.Second))
+	l.Allow("busy", start.Add(time.Second))
+
+	if dropped := l.Sweep(start.Add(time.Second)); dropped != 1 || l.Len() != 1 {
+		t.Errorf("Sweep dropped %d and kept %d buckets, want 1 and 1", dropped, l.Len())
+	}
+	if dropped := l.Sweep(start.Add(3 * time.Second)); dropped != 1 || l.Len() != 0 {
+		t.Errorf("Sweep dropped %d and kept %d buckets, want 1 and 0", dropped, l.Len())
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		header   string
+		identity *auth.Identity
+		want     string
+	}{
+		{"anonymous", "", nil, "ip:192.0.2.1"},
+		{"unverified bearer token", "Bearer made-up", nil, "ip:192.0.2.1"},
+		{"browser session", "", &auth.Identity{UserID: 7}, "ip:192.0.2.1"},
+		{"verified API token", "Bearer real", &auth.Identity{UserID: 7, TokenID: 42}, "token:42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/pages", nil)
+			ctx.Request.RemoteAddr = "192.0.2.1:1234"
+			if tt.header != "" {
+				ctx.Request.Header.Set("Authorization", tt.header)
+			}
+			if tt.identity != nil {
+				ctx.Request = ctx.Request.WithContext(auth.WithIdentity(ctx.Request.Context(), tt.identity))
+			}
+
+			if got := ClientKey(ctx); got != tt.want {
+				t.Errorf("ClientKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus []int
+	}{
+		{"page route", "/shared/:slug", []int{http.StatusOK, http.StatusTooManyRequests}},
+		{"API route", "/api/v1/pages", []int{http.StatusOK, http.StatusTooManyRequests}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET(tt.path, New("test", Limit{PerMinute: 1, Burst: 1}).Middleware(), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			for i, want := range tt.wantStatus {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if w.Code != want {
+					t.Fatalf("request %d: status %d, want %d", i, w.Code, want)
+				}
+				if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+					t.Errorf("Retry-After = %q, want %q", w.Header().Get("Retry-After"), "60")
+				}
+			}
+		})
+	}
+
+	// A nil limiter lets every request through
+	r := gin.New()
+	var disabled *Limiter
+	r.GET("/", disabled.Middleware(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d with no limit: status %d, want %d", i, w.Code, http.StatusOK)
+		}
+	}
+}
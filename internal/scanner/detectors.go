@@ -0,0 +1,219 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// credentialForms finds forms collecting passwords or payment card details that submit them to another domain
+type credentialForms struct {
+	rule       CredentialFormRule
+	cardFields []*regexp.Regexp
+	allowed    domainList
+}
+
+// Name identifies the detector in findings
+func (d *credentialForms) Name() string {
+	return "credential_form"
+}
+
+// Detect reports each kind of credential sent to a foreign domain once, naming the domains
+func (d *credentialForms) Detect(doc *Document) []Finding {
+	var passwordHosts, cardHosts []string
+	for _, form := range doc.Forms {
+		var hosts []string
+		for _, action := range form.Actions {
+			if host, foreign := d.allowed.foreign(action); foreign && !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) == 0 {
+			continue
+		}
+
+		for _, field := range form.Fields {
+			if field.Type == "password" {
+				passwordHosts = appendNew(passwordHosts, hosts...)
+			}
+			if d.isCardField(field) {
+				cardHosts = appendNew(cardHosts, hosts...)
+			}
+		}
+	}
+
+	var findings []Finding
+	if len(passwordHosts) > 0 {
+		findings = append(findings, Finding{
+			Category: CategoryPhishing,
+			Score:    d.rule.PasswordScore,
+			Detail:   "password form posts to " + strings.Join(passwordHosts, ", "),
+		})
+	}
+	if len(cardHosts) > 0 {
+		findings = append(findings, Finding{
+			Category: CategoryPhishing,
+			Score:    d.rule.CardScore,
+			Detail:   "payment card form posts to " + strings.Join(cardHosts, ", "),
+		})
+	}
+	return findings
+}
+
+// isCardField reports whether a field looks like it asks for payment card details
+func (d *credentialForms) isCardField(field Field) bool {
+	for _, value := range []string{field.Name, field.ID, field.Placeholder, field.Autocomplete, field.Label} {
+		if value == "" {
+			continue
+		}
+		for _, pattern := range d.cardFields {
+			if pattern.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// brandTitles finds page titles naming brands that phishing pages commonly imitate
+type brandTitles struct {
+	score  int
+	brands *regexp.Regexp
+}
+
+// Name identifies the detector in findings
+func (d *brandTitles) Name() string {
+	return "brand_title"
+}
+
+// Detect reports the brands named in the title
+func (d *brandTitles) Detect(doc *Document) []Finding {
+	var found []string
+	for _, brand := range d.brands.FindAllString(doc.Title, -1) {
+		found = appendNew(found, brand)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return []Finding{{
+		Category: CategoryPhishing,
+		Score:    d.score,
+		Detail:   fmt.Sprintf("title %q names %s", doc.Title, strings.Join(found, ", ")),
+	}}
+}
+
+// obfuscatedScripts finds scripts that decode and run hidden code
+type obfuscatedScripts struct {
+	score    int
+	patterns []*regexp.Regexp
+}
+
+// Name identifies the detector in findings
+func (d *obfuscatedScripts) Name() string {
+	return "obfuscated_script"
+}
+
+// Detect reports the first script matching a pattern
+func (d *obfuscatedScripts) Detect(doc *Document) []Finding {
+	for _, script := range doc.Scripts {
+		for _, pattern := range d.patterns {
+			if match := pattern.FindString(script); match != "" {
+				return []Finding{{
+					Category: CategoryMalware,
+					Score:    d.score,
+					Detail:   fmt.Sprintf("script contains %q", truncate(match, 60)),
+				}}
+			}
+		}
+	}
+	return nil
+}
+
+// metaRefresh finds <meta http-equiv="refresh"> redirects to other domains
+type metaRefresh struct {
+	score   int
+	allowed domainList
+}
+
+// Name identifies the detector in findings
+func (d *metaRefresh) Name() string {
+	return "meta_refresh"
+}
+
+// refreshURL matches the target of a refresh such as "0; url=https://example.com"
+var refreshURL = regexp.MustCompile(`(?i)^\s*[\d.]*\s*[;,]?\s*(?:url\s*=\s*)?['"]?([^'"]*)`)
+
+// Detect reports the first redirect to a foreign domain
+func (d *metaRefresh) Detect(doc *Document) []Finding {
+	for _, content := range doc.Refreshes {
+		match := refreshURL.FindStringSubmatch(content)
+		if match == nil || strings.TrimSpace(match[1]) == "" {
+			continue
+		}
+		if host, foreign := d.allowed.foreign(match[1]); foreign {
+			return []Finding{{
+				Category: CategoryPhishing,
+				Score:    d.score,
+				Detail:   "meta refresh redirects to " + host,
+			}}
+		}
+	}
+	return nil
+}
+
+// domainList is a set of allowed domains, each including its subdomains
+type domainList []string
+
+// newDomainList normalises domains for matching
+func newDomainList(domains []string) domainList {
+	list := make(domainList, 0, len(domains))
+	for _, domain := range domains {
+		if domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), "."); domain != "" {
+			list = append(list, domain)
+		}
+	}
+	return list
+}
+
+// foreign reports whether a URL points to a domain that is not allowed,
+// returning the domain. Relative URLs stay on this site and are not foreign.
+func (l domainList) foreign(rawURL string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme == "mailto" {
+		return "mailto:" + u.Opaque, true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return "", false
+	}
+	for _, domain := range l {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return host, false
+		}
+	}
+	return host, true
+}
+
+// appendNew appends the values not already in a slice
+func appendNew(list []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// truncate shortens s to at most n bytes, marking that it was cut
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "…"
+}
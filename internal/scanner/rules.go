@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules configure the built-in detectors and the thresholds that turn a score
+// into a verdict. A detector whose score is zero is disabled.
+type Rules struct {
+	Thresholds Thresholds `yaml:"thresholds" toml:"thresholds"`
+	// AllowedDomains are domains that forms and redirects may point to without
+	// counting as foreign; their subdomains are allowed too
+	AllowedDomains    []string             `yaml:"allowed_domains" toml:"allowed_domains"`
+	CredentialForms   CredentialFormRule   `yaml:"credential_forms" toml:"credential_forms"`
+	BrandTitles       BrandTitleRule       `yaml:"brand_titles" toml:"brand_titles"`
+	ObfuscatedScripts ObfuscatedScriptRule `yaml:"obfuscated_scripts" toml:"obfuscated_scripts"`
+	MetaRefresh       MetaRefreshRule      `yaml:"meta_refresh" toml:"meta_refresh"`
+}
+
+// Thresholds are the scores at which content is flagged, quarantined or
+// blocked; a zero threshold disables that verdict
+type Thresholds struct {
+	Flag       int `yaml:"flag" toml:"flag"`
+	Quarantine int `yaml:"quarantine" toml:"quarantine"`
+	Block      int `yaml:"block" toml:"block"`
+}
+
+// CredentialFormRule scores forms that collect passwords or payment card details
+// and submit them to another domain
+type CredentialFormRule struct {
+	// PasswordScore is added when a form with a password field posts to a foreign domain
+	PasswordScore int `yaml:"password_score" toml:"password_score"`
+	// CardScore is added when a form with payment card fields posts to a foreign domain
+	CardScore int `yaml:"card_score" toml:"card_score"`
+	// CardFields are regular expressions matched against a field's name, ID,
+	// placeholder, autocomplete hint and label to recognise card fields
+	CardFields []string `yaml:"card_fields" toml:"card_fields"`
+}
+
+// BrandTitleRule scores page titles naming brands that phishing pages commonly imitate
+type BrandTitleRule struct {
+	Score int `yaml:"score" toml:"score"`
+	// Brands are matched as whole words, ignoring case
+	Brands []string `yaml:"brands" toml:"brands"`
+}
+
+// ObfuscatedScriptRule scores scripts that decode and run hidden code
+type ObfuscatedScriptRule struct {
+	Score int `yaml:"score" toml:"score"`
+	// Patterns are regular expressions matched against inline scripts, event handlers and javascript: URLs
+	Patterns []string `yaml:"patterns" toml:"patterns"`
+	// MinEncodedLength is the length from which a base64 string passed to atob
+	// counts as a hidden payload, or 0 to not look for them
+	MinEncodedLength int `yaml:"min_encoded_length" toml:"min_encoded_length"`
+}
+
+// MetaRefreshRule scores <meta http-equiv="refresh"> redirects to other domains
+type MetaRefreshRule struct {
+	Score int `yaml:"score" toml:"score"`
+}
+
+// maxEncodedLength is the largest MinEncodedLength, the most repetitions a regular expression allows
+const maxEncodedLength = 1000
+
+// DefaultRules returns the built-in rules
+func DefaultRules() *Rules {
+	return &Rules{
+		Thresholds: Thresholds{Flag: 30, Quarantine: 60, Block: 100},
+		CredentialForms: CredentialFormRule{
+			PasswordScore: 50,
+			CardScore:     60,
+			CardFields: []string{
+				`(?i)^cc-(number|csc|exp)`,
+				`(?i)card.?(num|no\b|number)`,
+				`(?i)\b(cvv2?|cvc2?|csc)\b`,
+				`(?i)security.?code`,
+			},
+		},
+		BrandTitles: BrandTitleRule{
+			Score: 25,
+			Brands: []string{
+				"PayPal", "Apple ID", "iCloud", "Microsoft", "Office 365", "Outlook", "OneDrive",
+				"Google", "Gmail", "Facebook", "Instagram", "WhatsApp", "Netflix", "Amazon", "eBay",
+				"DHL", "FedEx", "UPS", "USPS", "Chase", "Wells Fargo", "Bank of America", "Citibank",
+				"HSBC", "Barclays", "Coinbase", "Binance", "MetaMask", "DocuSign", "Dropbox", "Adobe",
+				"LinkedIn", "Steam",
+			},
+		},
+		ObfuscatedScripts: ObfuscatedScriptRule{
+			Score: 50,
+			Patterns: []string{
+				`eval\s*\(\s*(window\.)?(atob|unescape|decodeURIComponent|escape|String\.fromCharCode)\s*\(`,
+				`(new\s+)?Function\s*\(\s*(window\.)?(atob|unescape|decodeURIComponent)\s*\(`,
+				`document\.write\s*\(\s*(window\.)?(atob|unescape|decodeURIComponent)\s*\(`,
+				`eval\s*\(\s*function\s*\(\s*p\s*,\s*a\s*,\s*c\s*,\s*k\s*,\s*e\s*,\s*[dr]\s*\)`,
+				`(\\x[0-9a-fA-F]{2}){40,}`,
+			},
+			MinEncodedLength: 200,
+		},
+		MetaRefresh: MetaRefreshRule{Score: 30},
+	}
+}
+
+// LoadRules reads rules from a YAML or TOML file. Settings missing from the
+// file keep their built-in values, and unknown keys are rejected. An empty
+// path returns the built-in rules.
+func LoadRules(path string) (*Rules, error) {
+	rules := DefaultRules()
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scanner rules: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(rules); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse scanner rules %s: %w", path, err)
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(rules); err != nil {
+			return nil, fmt.Errorf("failed to parse scanner rules %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("scanner rules %s must end in .yaml, .yml or .toml", path)
+	}
+
+	if _, err := rules.detectors(); err != nil {
+		return nil, fmt.Errorf("invalid scanner rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// detectors builds the built-in detectors the rules enable, compiling their patterns
+func (r *Rules) detectors() ([]Detector, error) {
+	var errs []error
+	var detectors []Detector
+	domains := newDomainList(r.AllowedDomains)
+
+	if r.CredentialForms.PasswordScore > 0 || r.CredentialForms.CardScore > 0 {
+		cardFields, err := compileAll("credential_forms.card_fields", r.CredentialForms.CardFields)
+		errs = append(errs, err)
+		detectors = append(detectors, &credentialForms{rule: r.CredentialForms, cardFields: cardFields, allowed: domains})
+	}
+
+	var brands []string
+	for _, brand := range r.BrandTitles.Brands {
+		if brand = strings.TrimSpace(brand); brand != "" {
+			brands = append(brands, regexp.QuoteMeta(brand))
+		}
+	}
+	if r.BrandTitles.Score > 0 && len(brands) > 0 {
+		pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(brands, "|") + `)\b`)
+		detectors = append(detectors, &brandTitles{score: r.BrandTitles.Score, brands: pattern})
+	}
+
+	if r.ObfuscatedScripts.Score > 0 {
+		patterns, err := compileAll("obfuscated_scripts.patterns", r.ObfuscatedScripts.Patterns)
+		errs = append(errs, err)
+		switch length := r.ObfuscatedScripts.MinEncodedLength; {
+		case length > maxEncodedLength:
+			errs = append(errs, fmt.Errorf("obfuscated_scripts.min_encoded_length must be at most %d", maxEncodedLength))
+		case length > 0:
+			patterns = append(patterns, regexp.MustCompile(fmt.Sprintf(`atob\s*\(\s*["'][A-Za-z0-9+/=\s]{%d,}["']`, length)))
+		}
+		detectors = append(detectors, &obfuscatedScripts{score: r.ObfuscatedScripts.Score, patterns: patterns})
+	}
+
+	if r.MetaRefresh.Score > 0 {
+		detectors = append(detectors, &metaRefresh{score: r.MetaRefresh.Score, allowed: domains})
+	}
+
+	if r.Thresholds.Flag < 0 || r.Thresholds.Quarantine < 0 || r.Thresholds.Block < 0 {
+		errs = append(errs, errors.New("thresholds must not be negative"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return detectors, nil
+}
+
+// compileAll compiles regular expressions, naming the setting they came from in errors
+func compileAll(setting string, patterns []string) ([]*regexp.Regexp, error) {
+	var errs []error
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, errors.Join(errs...)
+}
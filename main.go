@@ -1,67 +1,342 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm/logger"
 
+	"sharer/internal/api"
+	"sharer/internal/config"
 	"sharer/internal/database"
+	"sharer/internal/health"
+	"sharer/internal/lifecycle"
+	"sharer/internal/links"
+	"sharer/internal/logging"
+	"sharer/internal/metrics"
 	"sharer/internal/modules/category"
 	"sharer/internal/modules/page"
+	"sharer/internal/modules/user"
+	"sharer/internal/openapi"
+	"sharer/internal/ratelimit"
 )
 
+// Intervals of the background housekeeping workers
+const (
+	// limitSweepInterval is how often idle rate limiter state is evicted
+	limitSweepInterval = time.Minute
+	// sessionReapInterval is how often expired sessions are deleted
+	sessionReapInterval = time.Hour
+	// contentBytesInterval is how often the content size metric is recomputed
+	contentBytesInterval = time.Minute
+)
+
+// rateLimiters throttles each group of routes; a nil limiter lets every request through
+type rateLimiters struct {
+	form *ratelimit.Limiter
+	api  *ratelimit.Limiter
+	view *ratelimit.Limiter
+}
+
+// newRateLimiters creates the limiters configured for each group of routes
+func newRateLimiters(cfg config.LimitConfig) rateLimiters {
+	limiter := func(name string, limit config.RateLimit) *ratelimit.Limiter {
+		return ratelimit.New(name, ratelimit.Limit{PerMinute: limit.PerMinute, Burst: limit.Burst})
+	}
+	return rateLimiters{
+		form: limiter("form", cfg.Form),
+		api:  limiter("api", cfg.API),
+		view: limiter("view", cfg.View),
+	}
+}
+
+// sweep evicts clients whose buckets have refilled from every limiter
+func (l rateLimiters) sweep(now time.Time) {
+	for _, limiter := range []*ratelimit.Limiter{l.form, l.api, l.view} {
+		if limiter != nil {
+			limiter.Sweep(now)
+		}
+	}
+}
+
 func main() {
-	// Initialize database
-	dbConfig := database.Config{
-		DSN:     "./sharer.db",
-		LogMode: logger.Silent, // Use Silent for production
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	db, err := database.NewConnection(dbConfig)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatal(err)
+	}
+}
+
+// runServe migrates the database and serves HTTP until SIGINT or SIGTERM, then drains
+// in-flight requests and background workers before closing the database
+func runServe(args []string) error {
+	cfg, err := loadConfig(newFlagSet("serve"), args)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return err
 	}
 
-	// Run migrations
-	if err := database.Migrate(db); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	pageCfg, err := scanningPageConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(cfg, true)
+	if err != nil {
+		return err
 	}
 
 	// Initialize layers
 	pageRepo := page.NewRepository(db)
-	pageService := page.NewService(pageRepo)
+	pageService := page.NewService(pageRepo, pageCfg)
 	pageController := page.NewController(pageService)
 
 	categoryRepo := category.NewRepository(db)
 	categoryService := category.NewService(categoryRepo)
 	categoryController := category.NewController(categoryService)
 
-	// Set Gin to release mode for production
-	gin.SetMode(gin.ReleaseMode)
+	userRepo := user.NewRepository(db)
+	userService := user.NewService(userRepo, userConfig(cfg))
+	userController := user.NewController(userService)
+
+	gin.SetMode(cfg.Server.Mode)
+
+	// Create Gin router; requests are logged as structured lines with their request ID
+	r := gin.New()
+	r.Use(logging.Middleware(slog.Default(), "/healthz", "/readyz"), logging.Recovery())
+	if cfg.Features.Metrics {
+		r.Use(metrics.Middleware())
+	}
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		database.Close(db)
+		return err
+	}
+
+	resolver, err := links.NewResolver(cfg.Server.BaseURL, cfg.Server.TrustedProxies)
+	if err != nil {
+		database.Close(db)
+		return err
+	}
+	r.Use(api.PublicURL(resolver), api.LimitBody(cfg.Uploads.MaxBodyBytes))
+
+	// Identify the user before any rate limiting, so requests are limited per
+	// verified API token and invalid tokens are refused outright
+	r.Use(userController.Sessions(), userController.APITokens())
+
+	limits := newRateLimiters(cfg.Limits)
+	registerRoutes(r, pageController, categoryController, userController, cfg.Features, limits)
 
-	// Create Gin router
-	r := gin.Default()
+	// Probes for the orchestrator: liveness never touches the database, readiness checks it
+	dbFile := database.FilePath(cfg.Database.Path)
+	checker := health.NewChecker()
+	checker.Add("database", health.DatabasePing(db))
+	checker.Add("migrations", health.Migrations(db))
+	checker.Add("writable", health.Writable(dbFile))
+	checker.Add("disk_space", health.DiskSpace(dbFile, cfg.Database.MinFreeBytes))
+	r.GET("/healthz", health.Live)
+	r.HEAD("/healthz", health.Live)
+	r.GET("/readyz", checker.Ready)
+	r.HEAD("/readyz", checker.Ready)
 
-	// Routes
+	if cfg.Features.Metrics {
+		if err := db.Use(metrics.GormPlugin{}); err != nil {
+			database.Close(db)
+			return err
+		}
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// Background workers
+	workers := lifecycle.New()
+	workers.Every("expired-session-reaper", sessionReapInterval, func(ctx context.Context) error {
+		_, err := userService.DeleteExpiredSessions(ctx)
+		return err
+	})
+	workers.Every("rate-limit-eviction", limitSweepInterval, func(ctx context.Context) error {
+		limits.sweep(time.Now())
+		return nil
+	})
+	if cfg.Features.Metrics {
+		refreshContentBytes := func(ctx context.Context) error {
+			stats, err := pageService.GetStats(ctx)
+			if err != nil {
+				return err
+			}
+			metrics.ContentBytes.Set(float64(stats.ContentBytes))
+			return nil
+		}
+		if err := refreshContentBytes(context.Background()); err != nil {
+			slog.Error("computing content size for metrics failed", "error", err)
+		}
+		workers.Every("content-size-metric", contentBytesInterval, refreshContentBytes)
+	}
+	if cfg.Pages.ReapInterval > 0 {
+		workers.Every("expired-page-reaper", time.Duration(cfg.Pages.ReapInterval), func(ctx context.Context) error {
+			_, err := pageService.DeleteExpiredPages(ctx)
+			return err
+		})
+	}
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+	}
+	// Event streams never finish on their own, so end them when shutdown begins
+	server.RegisterOnShutdown(pageService.CloseSubscriptions)
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	slog.Info("server starting", "addr", cfg.Server.Addr)
+
+	select {
+	case err = <-serveErr:
+		// The server failed to start or stopped on its own
+	case <-signals.Done():
+		stop()
+		slog.Info("shutting down, waiting for requests and workers", "timeout", time.Duration(cfg.Server.ShutdownTimeout))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+		defer cancel()
+
+		if err = server.Shutdown(ctx); err != nil {
+			slog.Error("HTTP server shutdown failed", "error", err)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancel()
+	if workerErr := workers.Shutdown(shutdownCtx); workerErr != nil {
+		slog.Error("background workers failed", "error", workerErr)
+	}
+
+	if closeErr := database.Close(db); closeErr != nil {
+		slog.Error("closing database failed", "error", closeErr)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// registerRoutes registers all HTTP routes on the router
+func registerRoutes(r *gin.Engine, pageController *page.Controller, categoryController *category.Controller, userController *user.Controller, features config.FeatureConfig, limits rateLimiters) {
+	formLimit := limits.form.Middleware()
+	apiLimit := limits.api.Middleware()
+	viewLimit := limits.view.Middleware()
+	signedIn := userController.RequireSignIn()
+	adminOnly := userController.RequireAdmin()
+
+	// Page routes
 	r.GET("/", pageController.Home)
 	r.GET("/pages", pageController.Index)
-	r.POST("/", pageController.CreateFromForm)
-	r.POST("/api/share", pageController.CreateFromAPI)
-	r.GET("/shared/:slug", pageController.GetSharedContent)
+	r.POST("/", formLimit, pageController.CreateFromForm)
+	r.POST("/pages/bulk", signedIn, pageController.Bulk)
+	r.POST("/api/pages/bulk", signedIn, pageController.Bulk)
+	r.GET("/shared/:slug", viewLimit, pageController.GetSharedContent)
+	r.POST("/shared/:slug", viewLimit, pageController.UnlockSharedContent)
+	r.GET("/shared/:slug/report", pageController.ReportForm)
+	r.POST("/shared/:slug/report", formLimit, pageController.SubmitReport)
+
+	// Dashboard of the signed-in user's pages
+	r.GET("/me/pages", pageController.MyPages)
+	r.GET("/me/pages/:slug/edit", pageController.MyPageEdit)
+	r.POST("/me/pages/:slug", pageController.MyPageUpdate)
+	r.POST("/me/pages/:slug/delete", pageController.MyPageDelete)
 
-	// Category routes
+	if features.RawUpload {
+		r.POST("/api/raw", apiLimit, pageController.CreateRaw)
+		r.PUT("/api/raw/:slug", pageController.UpdateRaw)
+	}
+
+	if features.API {
+		r.POST("/api/share", apiLimit, pageController.CreateFromAPI)
+
+		// Versioned JSON API
+		v1 := r.Group("/api/v1")
+		v1.POST("/pages", apiLimit, pageController.APICreate)
+		v1.GET("/pages", pageController.APIList)
+		v1.GET("/pages/:slug", pageController.APIShow)
+		v1.PATCH("/pages/:slug", pageController.APIUpdate)
+		v1.DELETE("/pages/:slug", pageController.APIDelete)
+		v1.POST("/categories", adminOnly, categoryController.APICreate)
+		v1.GET("/categories", categoryController.APIList)
+		v1.GET("/categories/lookup", categoryController.APILookup)
+		v1.GET("/categories/:id", categoryController.APIShow)
+		v1.PATCH("/categories/:id", adminOnly, categoryController.APIUpdate)
+		v1.DELETE("/categories/:id", adminOnly, categoryController.APIDelete)
+		v1.POST("/categories/:id/merge", adminOnly, categoryController.APIMerge)
+	}
+
+	// Account routes
+	r.GET("/signup", userController.SignUpForm)
+	r.POST("/signup", formLimit, userController.SignUp)
+	r.GET("/signin", userController.SignInForm)
+	r.POST("/signin", formLimit, userController.SignIn)
+	r.POST("/signout", userController.SignOut)
+	r.GET("/account/tokens", userController.Tokens)
+	r.POST("/account/tokens", formLimit, userController.CreateToken)
+	r.POST("/account/tokens/:id/revoke", userController.RevokeToken)
+
+	// Category routes; browsing is public, managing categories is for administrators
 	r.GET("/categories", categoryController.Index)
-	r.GET("/categories/create", categoryController.Create)
-	r.POST("/categories", categoryController.Store)
+	r.GET("/categories/create", adminOnly, categoryController.Create)
+	r.POST("/categories", adminOnly, categoryController.Store)
 	r.GET("/categories/:id", categoryController.Show)
-	r.GET("/categories/:id/edit", categoryController.Edit)
-	r.GET("/categories/:id/edit-modal", categoryController.EditModal)
-	r.PUT("/categories/:id", categoryController.Update)
-	r.DELETE("/categories/:id", categoryController.Delete)
+	r.GET("/categories/:id/edit", adminOnly, categoryController.Edit)
+	r.GET("/categories/:id/edit-modal", adminOnly, categoryController.EditModal)
+	r.GET("/categories/:id/delete-modal", adminOnly, categoryController.DeleteModal)
+	r.PUT("/categories/:id", adminOnly, categoryController.Update)
+	r.DELETE("/categories/:id", adminOnly, categoryController.Delete)
+	r.GET("/categories/:id/merge-modal", adminOnly, categoryController.MergeModal)
+	r.POST("/categories/:id/merge", adminOnly, categoryController.Merge)
 	r.GET("/api/categories", categoryController.GetAllForDropdown)
 
-	fmt.Println("Server starting on :8080")
-	log.Fatal(r.Run(":8080"))
+	// Administration, for users with the admin role
+	admin := r.Group("/admin", adminOnly)
+	admin.GET("", pageController.AdminPages)
+	admin.GET("/pages", pageController.AdminPages)
+	admin.POST("/pages/:id/delete", pageController.AdminHardDelete)
+	admin.POST("/pages/:id/restore", pageController.AdminRestore)
+	admin.POST("/pages/:id/reinstate", pageController.AdminReinstate)
+	admin.GET("/reports", pageController.AdminReports)
+	admin.POST("/reports/:id/dismiss", pageController.AdminDismissReport)
+	admin.POST("/reports/:id/takedown", pageController.AdminTakeDown)
+	admin.GET("/users", userController.AdminUsers)
+	admin.POST("/users/:id/ban", userController.AdminBan)
+	admin.POST("/users/:id/unban", userController.AdminUnban)
+
+	// API description
+	r.GET("/api/openapi.json", openapi.Handler)
 }
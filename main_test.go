@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"sharer/internal/config"
+	"sharer/internal/modules/category"
+	"sharer/internal/modules/page"
+	"sharer/internal/modules/user"
+	"sharer/internal/openapi"
+)
+
+// pathParam matches Gin path parameters such as :slug
+var pathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+func TestOpenAPISpecCoversAPIRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	registerRoutes(r, page.NewController(nil), category.NewController(nil), user.NewController(nil), config.Default().Features, rateLimiters{})
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec(), &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	checked := 0
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		checked++
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		operations, ok := spec.Paths[path]
+		if !ok {
+			t.Errorf("route %s %s is missing from openapi.json (expected path %q)", route.Method, route.Path, path)
+			continue
+		}
+		if _, ok := operations[strings.ToLower(route.Method)]; !ok {
+			t.Errorf("route %s %s has no %s operation in openapi.json", route.Method, route.Path, strings.ToLower(route.Method))
+		}
+	}
+
+	if checked == 0 {
+		t.Fatal("no /api routes registered")
+	}
+}
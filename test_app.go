@@ -1,3 +1,6 @@
+//go:build ignore
+
+// Smoke test against a running server: go run test_app.go
 package main
 
 import (
@@ -74,7 +77,8 @@ func main() {
 		
 		// Test 3: Access shared content
 		fmt.Println("\nTest 3: Testing shared content access...")
-		sharedURL := "http://localhost:8080" + shareResp["url"]
+		// The API returns an absolute URL
+		sharedURL := shareResp["url"]
 		resp, err = http.Get(sharedURL)
 		if err != nil {
 			fmt.Printf("❌ Shared content access failed: %v\n", err)